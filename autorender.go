@@ -0,0 +1,120 @@
+package ProgressBar
+
+import "time"
+
+// SetAutoRender 按固定间隔在后台自动重绘，使耗时和剩余时间在没有更新时也能走动；
+// 0 或负数表示关闭，Finish 时自动停止
+func (c *Config) SetAutoRender(d time.Duration) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopAutoRender()
+	if d > 0 && !c.finished {
+		stop := make(chan struct{})
+		c.autoStop = stop
+		go c.autoRenderLoop(d, stop)
+	}
+	return c
+}
+
+// SetRefreshOnlyOnStateChange 自动重绘时跳过进度没有变化的帧，减少停滞时的无效输出；
+// clockInterval > 0 时停滞期间仍按该间隔刷新一次，让耗时继续走动
+func (c *Config) SetRefreshOnlyOnStateChange(flag bool, clockInterval time.Duration) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onlyOnChange = flag
+	c.stallClock = clockInterval
+	return c
+}
+
+func (c *Config) autoRenderLoop(d time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(d)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.Tick()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Tick 按统一的输出规则输出一帧，头部动画(SetHeadAnimation)和活动指示器(SetSpinner)随每次输出前进一帧；
+// 自动重绘的后台循环每个间隔调用一次，测试中可以配合 SetClock 设置的时钟直接调用，逐帧检查输出而不必等待真实时间
+func (c *Config) Tick() {
+	c.mu.Lock()
+	defer c.unlock()
+	if c.finished || c.complete() {
+		return
+	}
+	c.maybeRender(false)
+}
+
+// SetClock 设置进度条使用的时钟，nil 表示 time.Now；开始时间按新时钟重新记录。
+// 测试中配合 Tick 使用固定的时钟，输出中的耗时、剩余时间和速度也是确定的
+func (c *Config) SetClock(now func() time.Time) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if now == nil {
+		now = time.Now
+	}
+	c.now = now
+	c.startTime = now()
+	return c
+}
+
+// SetHeartbeat 开启低频心跳：超过 d 没有输出时重绘一次，只为让耗时和剩余时间继续走动，
+// 避免长时间没有更新时看起来像卡死；0 或负数表示关闭，Finish/Close 时自动停止
+func (c *Config) SetHeartbeat(d time.Duration) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopHeartbeat()
+	if d > 0 && !c.finished {
+		stop := make(chan struct{})
+		c.heartbeatStop = stop
+		go c.heartbeatLoop(d, stop)
+	}
+	return c
+}
+
+func (c *Config) heartbeatLoop(d time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(d)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.mu.Lock()
+			c.heartbeat(d)
+			c.unlock()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// 距上次输出已超过 d 时重绘一帧，调用方需持有锁；d 小于刷新间隔时按刷新间隔，
+// 心跳不会使输出超过设置的频率
+func (c *Config) heartbeat(d time.Duration) {
+	if c.finished || c.complete() || c.lastRender.IsZero() {
+		return
+	}
+	if c.now().Sub(c.lastRender) >= max(d, c.renderInterval()) {
+		c.maybeRender(true)
+	}
+}
+
+// 停止心跳，调用方需持有锁
+func (c *Config) stopHeartbeat() {
+	if c.heartbeatStop != nil {
+		close(c.heartbeatStop)
+		c.heartbeatStop = nil
+	}
+}
+
+// 停止自动重绘，调用方需持有锁
+func (c *Config) stopAutoRender() {
+	if c.autoStop != nil {
+		close(c.autoStop)
+		c.autoStop = nil
+	}
+}
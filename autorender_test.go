@@ -0,0 +1,143 @@
+package ProgressBar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAutoTickRepaints(t *testing.T) {
+	c, clk, buf := newTestBar(10, 40)
+	c.ShowUsedTime(true)
+	c.Update(3)
+	buf.Reset()
+	clk.advance(1000)
+	c.Tick()
+	if !strings.Contains(buf.String(), "00:00:01") {
+		t.Errorf("auto tick should advance the clock: %q", buf.String())
+	}
+}
+
+func TestRefreshOnlyOnStateChange(t *testing.T) {
+	c, clk, buf := newTestBar(10, 40)
+	c.SetRefreshOnlyOnStateChange(true, 5*time.Second)
+	c.Update(3)
+	buf.Reset()
+
+	clk.advance(1000)
+	c.Tick() // 没有变化，跳过
+	if buf.Len() != 0 {
+		t.Fatalf("stalled frame should be skipped: %q", buf.String())
+	}
+
+	clk.advance(4000)
+	c.Tick() // 停滞已达时钟间隔，刷新
+	if strings.Count(buf.String(), "\r") != 1 {
+		t.Fatalf("clock refresh expected: %q", buf.String())
+	}
+
+	buf.Reset()
+	c.mu.Lock()
+	c.current = 4
+	c.mu.Unlock()
+	c.Tick() // 有变化，刷新
+	if strings.Count(buf.String(), "\r") != 1 {
+		t.Errorf("changed frame should be painted: %q", buf.String())
+	}
+}
+
+func TestAutoRenderStopsOnFinish(t *testing.T) {
+	c, _, _ := newTestBar(10, 40)
+	c.SetAutoRender(time.Millisecond)
+	stop := c.autoStop
+	c.Finish()
+	select {
+	case <-stop:
+	default:
+		t.Fatal("Finish should stop auto render")
+	}
+	if c.autoStop != nil {
+		t.Error("autoStop should be cleared")
+	}
+}
+
+func TestTickAdvancesFrame(t *testing.T) {
+	c, _, buf := newTestBar(10, 30)
+	c.SetHeadAnimation([]string{">", "»"}).SetSpinner(true)
+	c.SetRenderOnUpdate(false).Update(5)
+	for i := 0; i < 3; i++ {
+		c.Tick()
+	}
+	want := "\r[=========>         ]  5/10 |" +
+		"\r[=========»         ]  5/10 /" +
+		"\r[=========>         ]  5/10 -"
+	if got := buf.String(); got != want {
+		t.Errorf("frames = %q, want %q", got, want)
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	c, clk, buf := newTestBar(10, 40)
+	c.ShowUsedTime(true)
+	c.Update(3)
+	buf.Reset()
+
+	c.mu.Lock()
+	clk.advance(500)
+	c.heartbeat(time.Second) // 刚输出过，不需要心跳
+	clk.advance(500)
+	c.heartbeat(time.Second)
+	c.mu.Unlock()
+	if strings.Count(buf.String(), "\r") != 1 || !strings.Contains(buf.String(), "00:00:01") {
+		t.Errorf("heartbeat should repaint once after a stall: %q", buf.String())
+	}
+
+	c.SetHeartbeat(time.Hour)
+	stop := c.heartbeatStop
+	c.Close()
+	select {
+	case <-stop:
+	default:
+		t.Fatal("Close should stop the heartbeat")
+	}
+}
+
+func TestHeartbeatRespectsRefreshInterval(t *testing.T) {
+	c, clk, buf := newTestBar(10, 40)
+	c.SetRefreshInterval(10 * time.Second)
+	c.Update(3)
+	buf.Reset()
+
+	c.mu.Lock()
+	for i := 0; i < 9; i++ {
+		clk.advance(1000)
+		c.heartbeat(time.Second) // 未到刷新间隔，不输出
+	}
+	if buf.Len() != 0 {
+		t.Errorf("heartbeat exceeded the refresh interval: %q", buf.String())
+	}
+	clk.advance(1000)
+	c.heartbeat(time.Second)
+	c.mu.Unlock()
+	if got := strings.Count(buf.String(), "\r"); got != 1 {
+		t.Errorf("renders = %d, want 1 after the refresh interval", got)
+	}
+}
+
+func TestSetClockDeterministicTicks(t *testing.T) {
+	clk := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	buf := &bytes.Buffer{}
+	c := ProgressBar(10).SetWidth(40).SetOutput(buf).SetMode(ModeInteractive).SetClock(clk.Now)
+	c.ShowUsedTime(true)
+	c.SetRenderOnUpdate(false).Update(5)
+	for i := 0; i < 2; i++ {
+		clk.advance(1000)
+		c.Tick()
+	}
+	want := "\r[======>     ]  5/10 [Elapsed:00:00:01]" +
+		"\r[======>     ]  5/10 [Elapsed:00:00:02]"
+	if got := buf.String(); got != want {
+		t.Errorf("frames = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,220 @@
+package ProgressBar
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// 平滑模式下不足一格时使用的 1/8 到 7/8 方块
+var partialBlocks = []string{"▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+// 平滑模式下已完成部分的字符
+const smoothFill = "█"
+
+// SetSmooth 平滑模式：已完成部分用 █ 填充，不足一格的部分用 1/8 方块表示，不显示 > 头部
+func (c *Config) SetSmooth(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.smooth = flag
+	return c
+}
+
+// SetHeadAnimation 设置依次循环的头部字符，如 []string{">", "»", "➤"}，每次渲染换一个，
+// 进度停滞时也能看出仍在运行；nil 或空切片表示使用固定的 ">"
+func (c *Config) SetHeadAnimation(frames []string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, f := range frames {
+		if err := validateGlyph(f); err != nil {
+			c.configError("SetHeadAnimation", f, err)
+			return c
+		}
+	}
+	c.headFrames = append([]string(nil), frames...)
+	c.headPos = 0
+	return c
+}
+
+// FillDirection 进度条的填充方向
+type FillDirection int
+
+const (
+	LeftToRight FillDirection = iota // 0: 从左向右(默认)
+	RightToLeft                      // 1: 从右向左，默认头部字符为 "<"
+	Center                           // 2: 从中间向两边，两端的默认头部字符为 "<" 和 ">"
+)
+
+// SetReverse 从右向左填充进度条，默认头部字符随之改为 "<"，宽度计算不变；
+// 等同于 SetFillDirection(RightToLeft)，false 时恢复为 LeftToRight
+func (c *Config) SetReverse(flag bool) *Config {
+	if flag {
+		return c.SetFillDirection(RightToLeft)
+	}
+	return c.SetFillDirection(LeftToRight)
+}
+
+// SetFillDirection 设置进度条的填充方向，默认 LeftToRight
+func (c *Config) SetFillDirection(dir FillDirection) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fillDir = dir
+	return c
+}
+
+// 本次渲染使用的头部字符，每次调用前进一帧，调用方需持有锁
+func (c *Config) nextHead() string {
+	if len(c.headFrames) == 0 {
+		if c.fillDir == RightToLeft {
+			return "<"
+		}
+		return ">"
+	}
+	head := c.headFrames[c.headPos%len(c.headFrames)]
+	c.headPos++
+	return head
+}
+
+// SetFillChar 设置已完成部分的字符，默认 "="；可以是宽字符(如 emoji)，按显示宽度排列
+func (c *Config) SetFillChar(s string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := validateGlyph(s); err != nil {
+		c.configError("SetFillChar", s, err)
+		return c
+	}
+	c.fillChar = s
+	return c
+}
+
+// Err 返回 SetFillChar 等设置方法累积的配置错误，没有错误时返回 nil；
+// 无效的设置被忽略并保留原来的值，链式调用结束后检查一次即可
+func (c *Config) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cfgErr
+}
+
+// 记录一个配置错误，调用方需持有锁
+func (c *Config) configError(setter, value string, err error) {
+	c.cfgErr = errors.Join(c.cfgErr, fmt.Errorf("ProgressBar: %s(%q): %w", setter, value, err))
+}
+
+// 校验进度条字符：不能为空，不能含控制字符等不可打印字符，显示宽度须大于 0
+func validateGlyph(s string) error {
+	if s == "" {
+		return errors.New("glyph is empty")
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) {
+			return fmt.Errorf("glyph contains non-printable character %U", r)
+		}
+	}
+	if displayWidth(s) <= 0 {
+		return errors.New("glyph has zero display width")
+	}
+	return nil
+}
+
+// 已完成部分的字符
+func (c *Config) fillGlyph() string {
+	if c.fillChar == "" {
+		return "="
+	}
+	return c.fillChar
+}
+
+// 生成宽度为 width 的进度条的已完成部分和未完成部分；
+// 进度达到 100% 时总是完全填满，不带头部或不足一格的字符。
+// 各字符按显示宽度排列，宽字符(如 emoji)不会使进度条超出 width 列
+func (c *Config) barCells(width int, percent float64) (filled, empty string) {
+	if width <= 0 {
+		return "", ""
+	}
+	if percent >= 100 {
+		if c.smooth {
+			return strings.Repeat(smoothFill, width), ""
+		}
+		return repeatToWidth(c.fillGlyph(), width), ""
+	}
+
+	cells := float64(width) * percent / 100
+	if c.smooth {
+		full := int(cells)
+		filled = strings.Repeat(smoothFill, full)
+		if eighths := int((cells - math.Floor(cells)) * 8); eighths > 0 {
+			filled += partialBlocks[eighths-1]
+			full++
+		}
+		return filled, repeatToWidth(c.emptyChar, width-full)
+	}
+
+	length := c.roundCells(cells)
+	if length >= width {
+		return repeatToWidth(c.fillGlyph(), width), ""
+	}
+	head := c.nextHead()
+	headWidth := displayWidth(head)
+	if length+headWidth > width {
+		length = max(width-headWidth, 0)
+	}
+	filled = repeatToWidth(c.fillGlyph(), length) + head
+	return filled, repeatToWidth(c.emptyChar, width-displayWidth(filled))
+}
+
+// 从中间向两边填充时生成两侧的未完成部分和中间的已完成部分，
+// 已完成部分两端各带一个头部字符，平滑模式下只用整格方块
+func (c *Config) centerCells(width int, percent float64) (left, filled, right string) {
+	if width <= 0 {
+		return "", "", ""
+	}
+	fill := c.fillGlyph()
+	if c.smooth {
+		fill = smoothFill
+	}
+	if percent >= 100 {
+		return "", repeatToWidth(fill, width), ""
+	}
+	length := c.roundCells(float64(width) * percent / 100)
+	if length >= width {
+		return "", repeatToWidth(fill, width), ""
+	}
+	var leftHead, rightHead string
+	if !c.smooth {
+		leftHead, rightHead = "<", ">"
+		if len(c.headFrames) > 0 {
+			leftHead = c.nextHead()
+			rightHead = leftHead
+		}
+	}
+	if heads := displayWidth(leftHead) + displayWidth(rightHead); length+heads > width {
+		length = max(width-heads, 0)
+	}
+	filled = leftHead + repeatToWidth(fill, length) + rightHead
+	rest := width - displayWidth(filled)
+	return repeatToWidth(c.emptyChar, rest/2), filled, repeatToWidth(c.emptyChar, rest-rest/2)
+}
+
+// 重复 s 填满 w 列，s 为宽字符放不下时用空格补足
+func repeatToWidth(s string, w int) string {
+	sw := displayWidth(s)
+	if w <= 0 {
+		return ""
+	}
+	if sw <= 0 {
+		return strings.Repeat(" ", w)
+	}
+	n := w / sw
+	return strings.Repeat(s, n) + strings.Repeat(" ", w-n*sw)
+}
+
+// 按字符反转，用于从右向左填充
+func reverseRunes(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
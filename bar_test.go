@@ -0,0 +1,206 @@
+package ProgressBar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBarCells(t *testing.T) {
+	tests := []struct {
+		smooth  bool
+		percent float64
+		want    string
+	}{
+		{false, 0, ">         "},
+		{false, 99.9, "=========>"},
+		{false, 100, "=========="},
+		{true, 0, "          "},
+		{true, 12.5, "█▎        "},
+		{true, 99.9, "█████████▉"},
+		{true, 100, "██████████"},
+	}
+	for _, tt := range tests {
+		c, _, _ := newTestBar(100, 40)
+		c.SetSmooth(tt.smooth)
+		filled, empty := c.barCells(10, tt.percent)
+		if got := filled + empty; got != tt.want {
+			t.Errorf("smooth=%v percent=%v: barCells = %q, want %q", tt.smooth, tt.percent, got, tt.want)
+		}
+	}
+}
+
+func TestCompletionSnapsToFullBar(t *testing.T) {
+	for _, smooth := range []bool{false, true} {
+		for _, mode := range []Rounding{RoundFloor, RoundNearest, RoundCeil} {
+			c, _, _ := newTestBar(1000, 23)
+			c.ShowProgress(false).SetSmooth(smooth).SetRounding(mode)
+			c.current = 999
+			almost := c.Render()
+			c.current = 1000
+			full := c.Render()
+			want := "[====================]"
+			if smooth {
+				want = "[████████████████████]"
+			}
+			if full != want {
+				t.Errorf("smooth=%v mode=%d: Render() = %q, want %q", smooth, mode, full, want)
+			}
+			if mode == RoundFloor && almost == full {
+				t.Errorf("smooth=%v: 99.9%% should not render as full: %q", smooth, almost)
+			}
+		}
+	}
+}
+
+func TestHeadAnimation(t *testing.T) {
+	c, _, _ := newTestBar(10, 20)
+	c.SetHeadAnimation([]string{">", "»", "➤"})
+	c.current = 5
+	for _, want := range []string{
+		"[=====>     ]  5/10",
+		"[=====»     ]  5/10",
+		"[=====➤     ]  5/10",
+		"[=====>     ]  5/10",
+	} {
+		if got := c.Render(); got != want {
+			t.Errorf("Render() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestReverse(t *testing.T) {
+	c, _, _ := newTestBar(10, 20)
+	c.SetReverse(true)
+	c.current = 5
+	if got, want := c.Render(), "[     <=====]  5/10"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	c.current = 10
+	if got, want := c.Render(), "[===========] 10/10"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestFillDirectionCenter(t *testing.T) {
+	c, _, _ := newTestBar(10, 20)
+	c.SetFillDirection(Center)
+	for cur, want := range map[int64]string{
+		0:  "[    <>     ]  0/10",
+		5:  "[  <=====>  ]  5/10",
+		9:  "[<=========>]  9/10",
+		10: "[===========] 10/10",
+	} {
+		c.current = cur
+		if got := c.Render(); got != want {
+			t.Errorf("current=%d: Render() = %q, want %q", cur, got, want)
+		}
+	}
+
+	c.SetSmooth(true)
+	c.current = 5
+	if got, want := c.Render(), "[   █████   ]  5/10"; got != want {
+		t.Errorf("smooth: Render() = %q, want %q", got, want)
+	}
+
+	c.SetReverse(false)
+	c.SetSmooth(false)
+	if got, want := c.Render(), "[=====>     ]  5/10"; got != want {
+		t.Errorf("SetReverse(false) should restore left-to-right, got %q", got)
+	}
+}
+
+func TestWideBarGlyphs(t *testing.T) {
+	c, _, _ := newTestBar(10, 20)
+	c.SetFillChar("🟩").SetEmptyChar("⬜").SetHeadAnimation([]string{"🚀"})
+	for _, cur := range []int64{0, 3, 5, 9, 10} {
+		c.current = cur
+		got := c.Render()
+		if w := displayWidth(got); w != 19 {
+			t.Errorf("current=%d: Render() = %q has width %d, want 19", cur, got, w)
+		}
+	}
+	c.current = 5
+	if got, want := c.Render(), "[🟩🟩 🚀⬜⬜]  5/10"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestSpinnerOnDeterminateBar(t *testing.T) {
+	c, _, _ := newTestBar(10, 20)
+	c.SetSpinner(true)
+	c.current = 5
+	for _, want := range []string{
+		"[====>    ]  5/10 |",
+		"[====>    ]  5/10 /",
+		"[====>    ]  5/10 -",
+		"[====>    ]  5/10 \\",
+		"[====>    ]  5/10 |",
+	} {
+		if got := c.Render(); got != want {
+			t.Errorf("Render() = %q, want %q", got, want)
+		}
+	}
+
+	c.SetSpinnerPosition(SpinnerBefore)
+	if got, want := c.Render(), "/ [====>    ]  5/10"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+
+	c.current = 10
+	if got, want := c.Render(), "[===========] 10/10"; got != want {
+		t.Errorf("completed bar should drop the spinner, got %q", got)
+	}
+}
+
+func TestGlyphValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		apply func(c *Config)
+	}{
+		{"empty fill", func(c *Config) { c.SetFillChar("") }},
+		{"newline fill", func(c *Config) { c.SetFillChar("=\n") }},
+		{"escape empty char", func(c *Config) { c.SetEmptyChar("\x1b[31m.") }},
+		{"combining mark empty char", func(c *Config) { c.SetEmptyChar("\u0301") }},
+		{"empty head frame", func(c *Config) { c.SetHeadAnimation([]string{">", ""}) }},
+	}
+	for _, tt := range tests {
+		c, _, _ := newTestBar(10, 20)
+		tt.apply(c)
+		if c.Err() == nil {
+			t.Errorf("%s: Err() = nil, want a configuration error", tt.name)
+		}
+		// 无效的设置被忽略，进度条照常输出
+		c.current = 5
+		if got, want := c.Render(), "[=====>     ]  5/10"; got != want {
+			t.Errorf("%s: Render() = %q, want %q", tt.name, got, want)
+		}
+		if err := c.Close(); err == nil {
+			t.Errorf("%s: Close() should report the configuration error", tt.name)
+		}
+	}
+
+	// 多个错误累积在一起
+	c, _, _ := newTestBar(10, 20)
+	c.SetFillChar("").SetEmptyChar("\t").SetFillChar("#")
+	err := c.Err()
+	if err == nil || !strings.Contains(err.Error(), "SetFillChar") || !strings.Contains(err.Error(), "SetEmptyChar") {
+		t.Errorf("Err() = %v, want both errors", err)
+	}
+	if c.fillChar != "#" {
+		t.Error("valid settings after an invalid one should still apply")
+	}
+
+	c, _, _ = newTestBar(10, 20)
+	c.SetFillChar("█").SetEmptyChar("░").SetHeadAnimation(nil)
+	if err := c.Err(); err != nil {
+		t.Errorf("Err() = %v for valid glyphs", err)
+	}
+}
+
+func TestGlyphErrorMessage(t *testing.T) {
+	c, _, _ := newTestBar(10, 20)
+	c.SetFillChar("")
+	if got, want := c.Err().Error(), `ProgressBar: SetFillChar(""): glyph is empty`; got != want {
+		t.Errorf("Err() = %q, want %q", got, want)
+	}
+}
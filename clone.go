@@ -0,0 +1,79 @@
+package ProgressBar
+
+// Clone 以当前进度条为模板复制一个新的进度条，总数相同，其余同 CloneWithTotal
+func (c *Config) Clone() *Config {
+	c.mu.Lock()
+	total := c.total
+	c.mu.Unlock()
+	return c.CloneWithTotal(total)
+}
+
+// CloneWithTotal 以当前进度条为模板创建总数为 total 的新进度条：复制样式、颜色、单位、
+// 格式化函数、回调等设置，进度、计时和渲染状态重新开始，与原进度条互不影响。
+// 附属计数器、自动重绘、心跳(SetHeartbeat)和 Ctrl+C 捕获不会复制，需要时在新进度条上重新设置；
+// 新进度条与原进度条共用输出目标，但不接管 SetOutputFile 打开的文件：原进度条 Close 后文件即关闭，
+// 新进度条需要另行 SetOutput 或 SetOutputFile
+func (c *Config) CloneWithTotal(total int64) *Config {
+	n := ProgressBar(total)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// 新进度条的窗口监听已在后台运行，复制期间同样持有它的锁
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.width = c.width
+	if c.resizeStop == nil {
+		n.stopResize()
+	}
+	n.out, n.outIsTTY, n.mode = c.out, c.outIsTTY, c.mode
+	n.summaryOut = c.summaryOut
+	n.now = c.now
+	n.startTime = n.now()
+
+	n.showProgress, n.showPercent, n.showSpeed = c.showProgress, c.showPercent, c.showSpeed
+	n.showUsedTime, n.showLastTime = c.showUsedTime, c.showLastTime
+	n.showSparkline, n.sparkSize = c.showSparkline, c.sparkSize
+	n.showOverflow, n.showAvgOnComplete = c.showOverflow, c.showAvgOnComplete
+	n.showIterCount, n.hideBar = c.showIterCount, c.hideBar
+	n.leftJustify, n.newlineOnResize, n.padLine = c.leftJustify, c.newlineOnResize, c.padLine
+	n.percentPos, n.widthRatio, n.percentBrackets = c.percentPos, c.widthRatio, c.percentBrackets
+	if c.countsBrackets != nil {
+		counts := *c.countsBrackets
+		n.countsBrackets = &counts
+	}
+
+	n.unit, n.iecLabels, n.sameUnit = c.unit, c.iecLabels, c.sameUnit
+	n.bytesPerUnit, n.thousandsSep, n.unknownTotal = c.bytesPerUnit, c.thousandsSep, c.unknownTotal
+	n.speedUnit, n.speedDecimals, n.speedNoAlign = c.speedUnit, c.speedDecimals, c.speedNoAlign
+	n.labels, n.elapsedFmt, n.etaFmt, n.shortTime = c.labels, c.elapsedFmt, c.etaFmt, c.shortTime
+	n.etaMinPercent, n.etaMaxJump = c.etaMinPercent, c.etaMaxJump
+	n.etaWindow, n.deadline = c.etaWindow, c.deadline
+
+	n.rounding, n.smooth, n.fillDir, n.emptyChar = c.rounding, c.smooth, c.fillDir, c.emptyChar
+	n.headFrames = append([]string(nil), c.headFrames...)
+	n.fillChar, n.spinner, n.spinnerPos = c.fillChar, c.spinner, c.spinnerPos
+	n.color, n.fillSGR, n.trackSGR, n.colorProfile = c.color, c.fillSGR, c.trackSGR, c.colorProfile
+	if c.darkBg != nil {
+		dark := *c.darkBg
+		n.darkBg = &dark
+	}
+
+	n.label, n.suffix, n.rawText, n.labelLine = c.label, c.suffix, c.rawText, c.labelLine
+	n.prefixFunc, n.suffixFunc = c.prefixFunc, c.suffixFunc
+	n.phases = append([]string(nil), c.phases...)
+	n.doneMsg, n.doneStyle = c.doneMsg, c.doneStyle
+
+	n.refreshInterval, n.renderDelta, n.manualRender = c.refreshInterval, c.renderDelta, c.manualRender
+	n.maxWrites = c.maxWrites
+	n.flushInterval.Store(int64(c.refreshInterval))
+	n.onlyOnChange, n.stallClock, n.minSample = c.onlyOnChange, c.stallClock, c.minSample
+	n.completeAt = c.completeAt
+	n.granularity.Store(c.granularity.Load())
+	n.syncUntilDone()
+
+	n.onRender, n.onFirstRender = c.onRender, c.onFirstRender
+
+	n.updateTotalStr()
+	return n
+}
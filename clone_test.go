@@ -0,0 +1,64 @@
+package ProgressBar
+
+import "testing"
+
+func TestCloneCopiesConfigNotState(t *testing.T) {
+	c, clk, buf := newTestBar(10, 30)
+	c.ShowPercent(true).SetLabel("copy").SetHeadAnimation([]string{">", "»"})
+	c.Update(5)
+	clk.advance(1000)
+
+	n := c.CloneWithTotal(20)
+	if n.resizeStop != nil {
+		t.Error("clone of a fixed-width bar should not watch resizes")
+	}
+	if n.current != 0 || n.lastLineWidth != 0 || n.firstRendered {
+		t.Error("clone should start with fresh progress and render state")
+	}
+	if got, want := n.Render(), "copy [>        ] 0.0% ( 0/20)"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+
+	n.PushPhase("a")
+	n.Update(10)
+	if len(c.phases) != 0 {
+		t.Error("clone should not share the phase stack")
+	}
+	if c.Snapshot().Current != 5 {
+		t.Error("updating the clone changed the original")
+	}
+	if n.out != buf {
+		t.Error("clone should share the output writer")
+	}
+
+	if got := c.Clone().Snapshot().Total; got != 10 {
+		t.Errorf("Clone total = %d, want 10", got)
+	}
+}
+
+func TestCloneCopiesLaterSettings(t *testing.T) {
+	c, _, _ := newTestBar(10, 30)
+	c.SetCompleteAt(8).SetFillChar("#").SetLabelOnOwnLine(true).SetAbbreviatedTime(true).
+		SetCountsBrackets(true).SetPercentBrackets(true)
+
+	n := c.Clone()
+	if n.completeAt != 8 {
+		t.Errorf("completeAt = %d, want 8", n.completeAt)
+	}
+	n.Update(5)
+	if got, want := n.Render(), "[#########>         ] ( 5/10)"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	if !n.labelLine {
+		t.Error("labelLine not copied")
+	}
+	if !n.shortTime {
+		t.Error("shortTime not copied")
+	}
+	if !n.percentBrackets || n.countsBrackets == nil || !*n.countsBrackets {
+		t.Error("bracket settings not copied")
+	}
+	if n.countsBrackets == c.countsBrackets {
+		t.Error("countsBrackets should not be shared with the original")
+	}
+}
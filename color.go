@@ -0,0 +1,232 @@
+package ProgressBar
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// 默认配色(SGR 参数)，浅色背景下亮绿色几乎不可见，改用深色
+const (
+	darkBgFill  = "92" // 亮绿
+	lightBgFill = "34" // 蓝
+)
+
+// SetColor 是否为进度条启用颜色
+func (c *Config) SetColor(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.color = flag
+	return c
+}
+
+// SetDarkBackground 指定终端背景深浅，覆盖根据 COLORFGBG 的自动检测
+func (c *Config) SetDarkBackground(dark bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.darkBg = &dark
+	return c
+}
+
+// SetFillColor 设置已完成部分的颜色(SGR 参数，如 "92" 或 "38;5;46")，空字符串表示按背景自动选择
+func (c *Config) SetFillColor(sgr string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fillSGR = sgr
+	return c
+}
+
+// SetTrackColor 设置未完成部分(轨道)的颜色，如暗灰 "90"，默认不着色
+func (c *Config) SetTrackColor(sgr string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.trackSGR = sgr
+	return c
+}
+
+// 当前背景是否为深色
+func (c *Config) isDarkBackground() bool {
+	if c.darkBg != nil {
+		return *c.darkBg
+	}
+	return detectDarkBackground(os.Getenv("COLORFGBG"))
+}
+
+// 根据 COLORFGBG(形如 "15;0" 或 "0;default;15") 判断背景深浅，无法判断时按深色处理
+func detectDarkBackground(colorfgbg string) bool {
+	parts := strings.Split(colorfgbg, ";")
+	bg, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return true
+	}
+	// 0-6 和 8 为深色，7 和 9-15 为浅色
+	return bg < 7 || bg == 8
+}
+
+// 已完成部分的颜色
+func (c *Config) fillColor() string {
+	if c.fillSGR != "" {
+		return c.fillSGR
+	}
+	if c.isDarkBackground() {
+		return darkBgFill
+	}
+	return lightBgFill
+}
+
+// 为文本加上颜色并按颜色深度降级，未启用颜色时原样返回
+func (c *Config) colorize(s, sgr string) string {
+	if !c.color || s == "" || sgr == "" {
+		return s
+	}
+	p := c.effectiveProfile()
+	if p == ColorNone {
+		return s
+	}
+	return "\x1b[" + downsampleSGR(sgr, p) + "m" + s + "\x1b[0m"
+}
+
+// ColorProfile 输出颜色的深度
+type ColorProfile int
+
+const (
+	ColorAuto ColorProfile = iota // 0: 根据 TERM/COLORTERM 自动检测(默认)
+	ColorNone                     // 1: 不输出颜色
+	Color16                       // 2: 16 色，256 色和真彩色降级为最接近的 16 色
+	Color256                      // 3: 256 色，真彩色降级为最接近的 256 色
+)
+
+// SetColorProfile 强制颜色深度，使颜色输出在测试和 CI 中保持确定
+func (c *Config) SetColorProfile(p ColorProfile) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.colorProfile = p
+	return c
+}
+
+// 当前生效的颜色深度
+func (c *Config) effectiveProfile() ColorProfile {
+	if c.colorProfile != ColorAuto {
+		return c.colorProfile
+	}
+	return detectColorProfile(os.Getenv("TERM"), os.Getenv("COLORTERM"))
+}
+
+// 根据环境变量判断颜色深度：声明真彩色或 256 色时按 256 色处理，否则按 16 色
+func detectColorProfile(term, colorterm string) ColorProfile {
+	if colorterm != "" || strings.Contains(term, "256color") {
+		return Color256
+	}
+	return Color16
+}
+
+// xterm 默认的 16 色 RGB 值，下标 0-7 对应 30-37，8-15 对应 90-97
+var ansi16 = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// 256 色色板中 6x6x6 色块每一级的取值
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// 按颜色深度改写 SGR 参数中的 256 色(38;5;n / 48;5;n)和真彩色(38;2;r;g;b / 48;2;r;g;b)
+func downsampleSGR(sgr string, p ColorProfile) string {
+	if p != Color16 && p != Color256 {
+		return sgr
+	}
+	parts := strings.Split(sgr, ";")
+	var out []string
+	for i := 0; i < len(parts); i++ {
+		if (parts[i] == "38" || parts[i] == "48") && i+2 < len(parts) {
+			bg := parts[i] == "48"
+			switch {
+			case parts[i+1] == "5":
+				n, err := strconv.Atoi(parts[i+2])
+				if err == nil && p == Color16 {
+					r, g, b := xterm256RGB(n)
+					out = append(out, ansi16Code(nearest16(r, g, b), bg))
+					i += 2
+					continue
+				}
+			case parts[i+1] == "2" && i+4 < len(parts):
+				r, e1 := strconv.Atoi(parts[i+2])
+				g, e2 := strconv.Atoi(parts[i+3])
+				b, e3 := strconv.Atoi(parts[i+4])
+				if e1 == nil && e2 == nil && e3 == nil {
+					if p == Color16 {
+						out = append(out, ansi16Code(nearest16(r, g, b), bg))
+					} else {
+						out = append(out, parts[i], "5", strconv.Itoa(nearestCube(r, g, b)))
+					}
+					i += 4
+					continue
+				}
+			}
+		}
+		out = append(out, parts[i])
+	}
+	return strings.Join(out, ";")
+}
+
+// 256 色编号对应的 RGB 值
+func xterm256RGB(n int) (r, g, b int) {
+	switch {
+	case n < 16:
+		c := ansi16[n&15]
+		return c[0], c[1], c[2]
+	case n < 232:
+		n -= 16
+		return cubeLevels[n/36], cubeLevels[n/6%6], cubeLevels[n%6]
+	case n < 256:
+		v := 8 + (n-232)*10
+		return v, v, v
+	}
+	return 255, 255, 255
+}
+
+// 与 RGB 最接近的 16 色下标
+func nearest16(r, g, b int) int {
+	best, bestDist := 0, -1
+	for i, c := range ansi16 {
+		dr, dg, db := r-c[0], g-c[1], b-c[2]
+		if d := dr*dr + dg*dg + db*db; bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// 与 RGB 最接近的 256 色色块编号
+func nearestCube(r, g, b int) int {
+	level := func(v int) int {
+		best := 0
+		for i, l := range cubeLevels {
+			if abs(v-l) < abs(v-cubeLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	return 16 + 36*level(r) + 6*level(g) + level(b)
+}
+
+// 16 色下标对应的 SGR 参数
+func ansi16Code(i int, bg bool) string {
+	code := 30 + i
+	if i >= 8 {
+		code = 90 + i - 8
+	}
+	if bg {
+		code += 10
+	}
+	return strconv.Itoa(code)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
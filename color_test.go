@@ -0,0 +1,88 @@
+package ProgressBar
+
+import "testing"
+
+func TestDetectDarkBackground(t *testing.T) {
+	tests := []struct {
+		env  string
+		dark bool
+	}{
+		{"", true},
+		{"15;0", true},
+		{"0;15", false},
+		{"0;default;7", false},
+		{"7;8", true},
+		{"garbage", true},
+	}
+	for _, tt := range tests {
+		if got := detectDarkBackground(tt.env); got != tt.dark {
+			t.Errorf("detectDarkBackground(%q) = %v, want %v", tt.env, got, tt.dark)
+		}
+	}
+}
+
+func TestColorPalette(t *testing.T) {
+	c, _, _ := newTestBar(10, 20)
+	c.SetColor(true).SetDarkBackground(true)
+	c.current = 5
+	want := "[\x1b[92m=====>\x1b[0m     ]  5/10"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+
+	c.SetDarkBackground(false)
+	want = "[\x1b[34m=====>\x1b[0m     ]  5/10"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestFillAndTrackColor(t *testing.T) {
+	c, _, _ := newTestBar(10, 20)
+	c.SetColor(true).SetFillColor("32").SetTrackColor("90").SetEmptyChar("-")
+	c.current = 5
+	want := "[\x1b[32m=====>\x1b[0m\x1b[90m-----\x1b[0m]  5/10"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	if w := displayWidth(want); w != 19 {
+		t.Errorf("displayWidth = %d, want 19", w)
+	}
+}
+
+func TestDownsampleSGR(t *testing.T) {
+	tests := []struct {
+		sgr     string
+		profile ColorProfile
+		want    string
+	}{
+		{"38;5;46", Color256, "38;5;46"},
+		{"38;5;46", Color16, "92"},
+		{"38;5;196", Color16, "91"},
+		{"48;5;240", Color16, "100"},
+		{"1;38;5;21", Color16, "1;34"},
+		{"38;2;0;255;0", Color256, "38;5;46"},
+		{"38;2;0;255;0", Color16, "92"},
+		{"92", Color16, "92"},
+	}
+	for _, tt := range tests {
+		if got := downsampleSGR(tt.sgr, tt.profile); got != tt.want {
+			t.Errorf("downsampleSGR(%q, %v) = %q, want %q", tt.sgr, tt.profile, got, tt.want)
+		}
+	}
+}
+
+func TestColorProfile(t *testing.T) {
+	c, _, _ := newTestBar(10, 20)
+	c.SetColor(true).SetFillColor("38;5;46").SetColorProfile(Color16)
+	c.current = 5
+	want := "[\x1b[92m=====>\x1b[0m     ]  5/10"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	c.SetColorProfile(ColorNone)
+	want = "[=====>     ]  5/10"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
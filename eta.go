@@ -0,0 +1,126 @@
+package ProgressBar
+
+import "time"
+
+// SetETAClamp 让剩余时间更稳定：进度低于 minPercent 时显示 --:--:--；
+// maxJump > 0 时，每次显示的值相对预期值(上次显示值减去经过的时间)的变化不超过该比例
+func (c *Config) SetETAClamp(minPercent, maxJump float64) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.etaMinPercent = minPercent
+	c.etaMaxJump = maxJump
+	c.shownETA = -1
+	return c
+}
+
+// 计算要显示的剩余时间，不可靠时返回 false，调用方需持有锁
+func (c *Config) displayETA(now time.Time, percent float64, eta time.Duration) (time.Duration, bool) {
+	eta, ok := c.clampETA(now, percent, eta)
+	if !ok {
+		return 0, false
+	}
+	c.shownETA = eta
+	c.shownETAAt = now
+	return eta, true
+}
+
+// 按 SetETAClamp 限制剩余时间，不记录显示值，ETA 方法与显示共用，调用方需持有锁
+func (c *Config) clampETA(now time.Time, percent float64, eta time.Duration) (time.Duration, bool) {
+	if eta < 0 || percent <= 0 || percent < c.etaMinPercent {
+		return 0, false
+	}
+	if c.etaMaxJump > 0 && c.shownETA >= 0 {
+		expected := c.shownETA - now.Sub(c.shownETAAt)
+		if expected < 0 {
+			expected = 0
+		}
+		lo := time.Duration(float64(expected) * (1 - c.etaMaxJump))
+		hi := time.Duration(float64(expected) * (1 + c.etaMaxJump))
+		if eta < lo {
+			eta = lo
+		} else if eta > hi {
+			eta = hi
+		}
+	}
+	return eta, true
+}
+
+// 剩余时间窗口中的一个采样
+type etaSample struct {
+	at    time.Time
+	value int64
+}
+
+// SetETAWindow 按最近 n 次渲染时的进度估算剩余时间：速度取窗口内最旧到最新采样之间的平均值，
+// 窗口越大越平滑，越小对速度变化反应越快；n < 2 表示按全程平均速度估算(默认)
+func (c *Config) SetETAWindow(n int) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n < 2 {
+		n = 0
+	}
+	c.etaWindow = n
+	c.etaSamples = nil
+	c.etaPos = 0
+	return c
+}
+
+// 记录一个剩余时间采样，缓冲区满后覆盖最旧的采样；同一时刻只保留最新的值，调用方需持有锁
+func (c *Config) pushETASample(now time.Time) {
+	if c.etaWindow == 0 {
+		return
+	}
+	n := len(c.etaSamples)
+	if n > 0 {
+		newest := (c.etaPos + n - 1) % n
+		if !now.After(c.etaSamples[newest].at) {
+			c.etaSamples[newest].value = c.current
+			return
+		}
+	}
+	s := etaSample{at: now, value: c.current}
+	if n < c.etaWindow {
+		c.etaSamples = append(c.etaSamples, s)
+		return
+	}
+	c.etaSamples[c.etaPos] = s
+	c.etaPos = (c.etaPos + 1) % n
+}
+
+// 窗口内最旧到最新采样之间的每秒数量，采样不足两个时返回 false，调用方需持有锁
+func (c *Config) windowRate() (float64, bool) {
+	n := len(c.etaSamples)
+	if n < 2 {
+		return 0, false
+	}
+	oldest := c.etaSamples[c.etaPos%n]
+	newest := c.etaSamples[(c.etaPos+n-1)%n]
+	span := newest.at.Sub(oldest.at)
+	if span <= 0 {
+		return 0, false
+	}
+	return float64(newest.value-oldest.value) / span.Seconds(), true
+}
+
+// 预计超出截止时间时剩余时间的颜色(黄色)
+const deadlineWarnSGR = "33"
+
+// SetDeadline 设置截止时间，预计完成的时刻(当前时间加剩余时间)晚于截止时间时，剩余时间用警告色显示，
+// 未启用颜色时在后面加 "!"；可以传入 ctx.Deadline() 的结果，零值表示不检查(默认)
+func (c *Config) SetDeadline(t time.Time) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deadline = t
+	return c
+}
+
+// 按截止时间标记格式化后的剩余时间，调用方需持有锁
+func (c *Config) markDeadline(now time.Time, eta time.Duration, s string) string {
+	if c.deadline.IsZero() || !now.Add(eta).After(c.deadline) {
+		return s
+	}
+	if c.color && c.effectiveProfile() != ColorNone {
+		return c.colorize(s, deadlineWarnSGR)
+	}
+	return s + "!"
+}
@@ -0,0 +1,143 @@
+package ProgressBar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestETAClampHidesEarlyEstimate(t *testing.T) {
+	c, clk, _ := newTestBar(1000, 60)
+	c.SetETAClamp(1, 0)
+	c.ShowLastTime(true)
+	clk.advance(1000)
+	c.current = 5
+	if got := c.Render(); !strings.HasSuffix(got, "[ETA:--:--:--]") {
+		t.Errorf("Render() = %q, want placeholder ETA", got)
+	}
+	c.current = 10
+	if got := c.Render(); !strings.HasSuffix(got, "[ETA:00:01:39]") {
+		t.Errorf("Render() = %q, want ETA 00:01:39", got)
+	}
+}
+
+func TestETAClampLimitsJumps(t *testing.T) {
+	c, clk, _ := newTestBar(100, 60)
+	c.SetETAClamp(0, 0.5)
+	c.ShowLastTime(true)
+	clk.advance(10000)
+	c.current = 50 // 原始 10s
+	if got := c.Render(); !strings.HasSuffix(got, "[ETA:00:00:10]") {
+		t.Fatalf("Render() = %q", got)
+	}
+	clk.advance(2000)
+	c.current = 51 // 原始约 11.5s，预期 8s，上限 12s
+	if got := c.Render(); !strings.HasSuffix(got, "[ETA:00:00:11]") {
+		t.Errorf("Render() = %q", got)
+	}
+	clk.advance(8000)
+	c.current = 52 // 原始约 18.5s，预期约 3.5s，上限约 5.3s
+	if got := c.Render(); !strings.HasSuffix(got, "[ETA:00:00:05]") {
+		t.Errorf("Render() = %q, want ETA capped to 5s", got)
+	}
+}
+
+func TestETAWindowRampingRate(t *testing.T) {
+	near := func(got, want time.Duration) bool {
+		d := got - want
+		return d > -time.Millisecond && d < time.Millisecond
+	}
+	c, clk, _ := newTestBar(1000, 60)
+	c.SetETAWindow(3)
+	c.Render()
+
+	// 窗口未满：按已有的两个采样估算
+	clk.advance(1000)
+	c.Update(10)
+	if eta, _ := c.ETA(); !near(eta, 99*time.Second) {
+		t.Errorf("partial window ETA = %v, want 99s", eta)
+	}
+
+	// 速度逐秒加快，窗口只反映最近两秒
+	total := int64(10)
+	for i := int64(2); i <= 5; i++ {
+		clk.advance(1000)
+		total += 10 * i
+		c.Update(total)
+	}
+	if len(c.etaSamples) != 3 {
+		t.Fatalf("ring buffer holds %d samples, want 3", len(c.etaSamples))
+	}
+	// 最近两秒从 60 到 150：45/s，剩余 850
+	want := 850 * time.Second / 45
+	if eta, ok := c.ETA(); !ok || !near(eta, want) {
+		t.Errorf("windowed ETA = %v, %v, want %v", eta, ok, want)
+	}
+
+	// 全程平均会高估剩余时间
+	c.SetETAWindow(0)
+	if eta, _ := c.ETA(); !near(eta, 5*time.Second*1000/150-5*time.Second) {
+		t.Errorf("average ETA = %v", eta)
+	}
+}
+
+func TestETAWindowStalled(t *testing.T) {
+	c, clk, _ := newTestBar(100, 60)
+	c.SetETAWindow(2)
+	clk.advance(1000)
+	c.Update(10)
+	clk.advance(1000)
+	c.Update(10)
+	if eta, ok := c.ETA(); ok {
+		t.Errorf("ETA() = %v, want unknown when the window saw no progress", eta)
+	}
+}
+
+func TestDeadlineMarksETA(t *testing.T) {
+	c, clk, _ := newTestBar(100, 60)
+	c.ShowLastTime(true)
+	start := clk.Now()
+	clk.advance(10000)
+	c.current = 50 // 剩余 10s，预计 20s 完成
+
+	c.SetDeadline(start.Add(30 * time.Second))
+	if got := c.Render(); !strings.HasSuffix(got, "[ETA:00:00:10]") {
+		t.Errorf("on schedule: Render() = %q", got)
+	}
+
+	c.SetDeadline(start.Add(15 * time.Second))
+	if got := c.Render(); !strings.HasSuffix(got, "[ETA:00:00:10!]") {
+		t.Errorf("missing deadline without color: Render() = %q", got)
+	}
+
+	c.SetColor(true).SetColorProfile(Color16)
+	if got := c.Render(); !strings.HasSuffix(got, "[ETA:\x1b[33m00:00:10\x1b[0m]") {
+		t.Errorf("missing deadline with color: Render() = %q", got)
+	}
+}
+
+func TestETAMatchesDisplay(t *testing.T) {
+	c, clk, _ := newTestBar(100, 60)
+	c.SetETAClamp(50, 0)
+	c.ShowLastTime(true)
+	clk.advance(1000)
+	c.current = 1
+	if got := c.Render(); !strings.HasSuffix(got, "[ETA:--:--:--]") {
+		t.Fatalf("Render() = %q", got)
+	}
+	if eta, ok := c.ETA(); ok || eta != -1 {
+		t.Errorf("ETA() = %v, %v, want -1, false below the clamp threshold", eta, ok)
+	}
+
+	// 限制变化比例时返回的值与下一次显示的值一致
+	c.SetETAClamp(0, 0.5)
+	clk.advance(9000)
+	c.current = 50 // 原始 10s
+	c.Render()
+	clk.advance(4000)
+	c.current = 51 // 原始约 13.5s，预期 6s，上限 9s
+	eta, ok := c.ETA()
+	if got := c.Render(); !ok || eta != 9*time.Second || !strings.HasSuffix(got, "[ETA:00:00:09]") {
+		t.Errorf("ETA() = %v, %v, display %q", eta, ok, got)
+	}
+}
@@ -0,0 +1,64 @@
+package ProgressBar
+
+import (
+	"os"
+	"os/signal"
+)
+
+// HandleInterrupt 捕获 Ctrl+C：先结束当前行并恢复光标，然后取消捕获并重新发送信号，
+// 程序仍按默认行为退出；Finish 后自动取消捕获
+func (c *Config) HandleInterrupt() *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.intrStop != nil || c.finished {
+		return c
+	}
+	sigs := make(chan os.Signal, 1)
+	stop := make(chan struct{})
+	c.intrStop = stop
+	signal.Notify(sigs, os.Interrupt)
+	go func() {
+		select {
+		case sig := <-sigs:
+			c.interrupt()
+			signal.Stop(sigs)
+			if p, err := os.FindProcess(os.Getpid()); err == nil {
+				p.Signal(sig)
+			}
+		case <-stop:
+			signal.Stop(sigs)
+		}
+	}()
+	return c
+}
+
+// 被中断时收尾：与 finish 一样停止所有后台 goroutine 并关闭 Done，换行保留已输出的进度，恢复光标
+func (c *Config) interrupt() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.finished {
+		return
+	}
+	c.finished = true
+	defer c.closeDone()
+	c.stopAutoRender()
+	c.stopHeartbeat()
+	c.stopInterrupt()
+	c.stopResize()
+	if c.plain() {
+		return
+	}
+	if c.lastLineWidth > 0 {
+		c.write("\n")
+		c.lastLineWidth = 0
+	}
+	c.write("\x1b[?25h")
+}
+
+// 取消中断捕获，调用方需持有锁
+func (c *Config) stopInterrupt() {
+	if c.intrStop != nil {
+		close(c.intrStop)
+		c.intrStop = nil
+	}
+}
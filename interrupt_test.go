@@ -0,0 +1,40 @@
+package ProgressBar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInterruptFinalizesLine(t *testing.T) {
+	c, _, buf := newTestBar(10, 20)
+	c.SetHeartbeat(time.Hour)
+	c.Update(5)
+	buf.Reset()
+	c.interrupt()
+	if got, want := buf.String(), "\n\x1b[?25h"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	if !c.finished {
+		t.Error("bar should be finished after interrupt")
+	}
+	select {
+	case <-c.Done():
+	default:
+		t.Error("Done should be closed after interrupt")
+	}
+	if c.resizeStop != nil || c.heartbeatStop != nil {
+		t.Error("interrupt should stop background goroutines")
+	}
+}
+
+func TestHandleInterruptStopsOnFinish(t *testing.T) {
+	c, _, _ := newTestBar(10, 20)
+	c.HandleInterrupt()
+	stop := c.intrStop
+	c.Finish()
+	select {
+	case <-stop:
+	default:
+		t.Fatal("Finish should cancel the interrupt handler")
+	}
+}
@@ -0,0 +1,189 @@
+package ProgressBar
+
+import (
+	"io"
+	"strings"
+	"unicode"
+)
+
+// SetLabel 设置显示在进度条最前面的说明文字，如 "下载中"，空字符串表示不显示
+func (c *Config) SetLabel(label string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.label = label
+	return c
+}
+
+// SetSuffix 设置显示在行尾的文字，如当前处理的文件名，空字符串表示不显示
+func (c *Config) SetSuffix(suffix string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.suffix = suffix
+	return c
+}
+
+// IncrementWithLabel 在同一次加锁内设置说明文字并加一，只输出一次，避免文字与计数不一致
+func (c *Config) IncrementWithLabel(label string) {
+	c.mu.Lock()
+	defer c.unlock()
+	if c.finished {
+		return
+	}
+	c.label = label
+	c.current++
+	c.changed()
+}
+
+// UpdateWithLabel 在同一次加锁内设置说明文字和当前值，只输出一次，负数按 0 处理
+func (c *Config) UpdateWithLabel(current int64, label string) {
+	c.mu.Lock()
+	defer c.unlock()
+	if c.finished {
+		return
+	}
+	if current < 0 {
+		current = 0
+	}
+	c.label = label
+	c.buffered.Store(0)
+	c.current = current
+	c.changed()
+}
+
+// PushPhase 进入一个子阶段，阶段栈以 "build > compile > link" 的形式显示在说明文字之后
+func (c *Config) PushPhase(name string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.phases = append(c.phases, name)
+	return c
+}
+
+// PopPhase 退出最近进入的阶段并返回它的名字，没有阶段时返回空字符串
+func (c *Config) PopPhase() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.phases) == 0 {
+		return ""
+	}
+	name := c.phases[len(c.phases)-1]
+	c.phases = c.phases[:len(c.phases)-1]
+	return name
+}
+
+// SetPrefixFunc 设置每次渲染时计算的行首文字，显示在 SetLabel 的文字之后，nil 表示取消；
+// 函数在持有锁时调用，参数是当前状态的只读副本，可以调用其 Snapshot、Percent、ETA 等方法，
+// 修改副本不影响进度条；不要在函数中调用原进度条(如闭包捕获的变量)的任何方法，否则会死锁
+func (c *Config) SetPrefixFunc(fn func(*Config) string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prefixFunc = fn
+	return c
+}
+
+// SetSuffixFunc 设置每次渲染时计算的行尾文字，显示在 SetSuffix 的文字之后，其余同 SetPrefixFunc
+func (c *Config) SetSuffixFunc(fn func(*Config) string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.suffixFunc = fn
+	return c
+}
+
+// SetRawText 是否原样输出说明文字和后缀，默认关闭：其中的换行、制表符等控制字符替换为空格，
+// ANSI 转义序列被去掉，避免文件名、错误信息等任意文字破坏单行进度条；
+// 需要在文字中自行着色时开启
+func (c *Config) SetRawText(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rawText = flag
+	return c
+}
+
+// 计算行首和行尾的文字(含分隔空格)；动态函数在持有锁时调用，整帧的渲染不会中途释放锁，调用方需持有锁
+func (c *Config) decorations() (prefix, suffix string) {
+	label, tail := joinText(c.label, strings.Join(c.phases, " > ")), c.suffix
+	if pf, sf := c.prefixFunc, c.suffixFunc; pf != nil || sf != nil {
+		view := c.stateView()
+		if pf != nil {
+			label = joinText(label, pf(view))
+		}
+		if sf != nil {
+			tail = joinText(tail, sf(view))
+		}
+	}
+	if !c.rawText {
+		label, tail = sanitizeText(label), sanitizeText(tail)
+	}
+	if label != "" {
+		prefix = label + " "
+	}
+	if tail != "" {
+		suffix = " " + tail
+	}
+	return prefix, suffix
+}
+
+// 供动态函数读取的状态副本：有自己的锁，调用其读取方法不会与持有锁的渲染死锁；
+// 不输出、不关闭原进度条的 Done，调用方需持有锁
+func (c *Config) stateView() *Config {
+	return &Config{
+		current:       c.current,
+		total:         c.total,
+		completeAt:    c.completeAt,
+		finished:      c.finished,
+		speed:         c.speed,
+		sampled:       c.sampled,
+		now:           c.now,
+		startTime:     c.startTime,
+		etaWindow:     c.etaWindow,
+		etaSamples:    append([]etaSample(nil), c.etaSamples...),
+		etaPos:        c.etaPos,
+		etaMinPercent: c.etaMinPercent,
+		etaMaxJump:    c.etaMaxJump,
+		shownETA:      c.shownETA,
+		shownETAAt:    c.shownETAAt,
+		label:         c.label,
+		suffix:        c.suffix,
+		phases:        append([]string(nil), c.phases...),
+		cfgErr:        c.cfgErr,
+		out:           io.Discard,
+		manualRender:  true,
+		done:          make(chan struct{}),
+	}
+}
+
+// 用空格连接两段文字，忽略空的一段
+func joinText(a, b string) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+	return a + " " + b
+}
+
+// 去掉 ANSI 转义序列，其余控制字符替换为空格，首尾的空白一并去掉
+func sanitizeText(s string) string {
+	clean := true
+	for _, r := range s {
+		if unicode.IsControl(r) {
+			clean = false
+			break
+		}
+	}
+	if clean {
+		return s
+	}
+	rs := []rune(s)
+	var b strings.Builder
+	for i := 0; i < len(rs); {
+		if j := skipEscape(rs, i); j > i {
+			i = j
+			continue
+		}
+		if unicode.IsControl(rs[i]) {
+			b.WriteByte(' ')
+		} else {
+			b.WriteRune(rs[i])
+		}
+		i++
+	}
+	return strings.TrimSpace(b.String())
+}
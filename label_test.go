@@ -0,0 +1,130 @@
+package ProgressBar
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRenderLabelAndSuffix(t *testing.T) {
+	c, _, _ := newTestBar(10, 30)
+	c.SetLabel("copy").SetSuffix("a.txt")
+	c.current = 5
+	want := "copy [=====>    ]  5/10 a.txt"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestIncrementWithLabel(t *testing.T) {
+	c, _, buf := newTestBar(10, 30)
+	c.IncrementWithLabel("a.txt")
+	c.UpdateWithLabel(3, "b.txt")
+	want := "\ra.txt [=>             ]  1/10\rb.txt [====>          ]  3/10"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestPrefixAndSuffixFunc(t *testing.T) {
+	c, _, _ := newTestBar(10, 30)
+	c.SetLabel("copy")
+	c.SetPrefixFunc(func(b *Config) string {
+		return fmt.Sprintf("chunk %d", b.Snapshot().Current/5+1)
+	})
+	c.SetSuffixFunc(func(*Config) string { return "ok" })
+	c.current = 5
+	want := "copy chunk 2 [==>  ]  5/10 ok"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestPhaseStack(t *testing.T) {
+	c, _, _ := newTestBar(10, 40)
+	c.SetShowBar(false)
+	c.current = 5
+	c.PushPhase("build").PushPhase("compile").PushPhase("link")
+	if got, want := c.Render(), "build > compile > link  5/10"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	if got := c.PopPhase(); got != "link" {
+		t.Errorf("PopPhase() = %q, want link", got)
+	}
+	c.SetLabel("make")
+	if got, want := c.Render(), "make build > compile  5/10"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	c.PopPhase()
+	c.PopPhase()
+	if got := c.PopPhase(); got != "" {
+		t.Errorf("PopPhase() on empty stack = %q", got)
+	}
+}
+
+func TestLabelOnOwnLine(t *testing.T) {
+	c, _, buf := newTestBar(10, 20)
+	c.SetLabel("copy a.txt").SetLabelOnOwnLine(true)
+	c.current = 5
+	lines := c.RenderLines()
+	if len(lines) != 2 || lines[0] != "copy a.txt" || lines[1] != "[=====>     ]  5/10" {
+		t.Fatalf("RenderLines() = %q", lines)
+	}
+
+	c.Update(6)
+	c.SetLabel("copy b.txt")
+	c.Update(10)
+	want := "\rcopy a.txt\x1b[K\n\r[======>    ]  6/10" +
+		"\x1b[1A\rcopy b.txt\x1b[K\n\r[===========] 10/10\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	c.SetLabelOnOwnLine(false)
+	if lines := c.RenderLines(); len(lines) != 1 {
+		t.Errorf("RenderLines() = %q, want one line", lines)
+	}
+}
+
+func TestLabelControlCharacters(t *testing.T) {
+	c, _, buf := newTestBar(10, 40)
+	c.SetLabel("line one\nline two").SetSuffix("\x1b[31merr\x1b[0m\tdone\r")
+	c.Update(5)
+	want := "line one line two [==> ]  5/10 err done"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	if strings.Contains(buf.String(), "\n") {
+		t.Errorf("label newline leaked into output: %q", buf.String())
+	}
+
+	c.SetRawText(true)
+	if got := c.Render(); !strings.HasPrefix(got, "line one\nline two ") {
+		t.Errorf("raw mode should keep text as-is, got %q", got)
+	}
+}
+
+func TestPrefixFuncConcurrentCompletion(t *testing.T) {
+	c, _, buf := newTestBar(100, 40)
+	c.SetPrefixFunc(func(b *Config) string {
+		time.Sleep(100 * time.Microsecond) // 让其他 goroutine 有机会在渲染中途插入
+		return fmt.Sprintf("%.0f%%", b.Percent())
+	})
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100/8+1; i++ {
+				c.Increment()
+			}
+		}()
+	}
+	wg.Wait()
+	c.Close()
+	if n := strings.Count(buf.String(), "\n"); n != 1 {
+		t.Errorf("completion newline written %d times", n)
+	}
+}
@@ -1,10 +1,15 @@
 package ProgressBar
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
-	"os/signal"
-	"syscall"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/term"
@@ -18,206 +23,1390 @@ const (
 	UnitBytes             // 1: 字节友好换算
 )
 
+// Rounding 进度条填充格数的取整方式
+type Rounding int
+
+const (
+	RoundFloor   Rounding = iota // 0: 向下取整(默认)
+	RoundNearest                 // 1: 四舍五入
+	RoundCeil                    // 2: 向上取整
+)
+
+// Labels 输出中使用的文字
+type Labels struct {
+	Elapsed   string // 已用时间
+	Remaining string // 剩余时间
+	Items     string // 原始数值的单位，用于速度，如 items/s
+	Processed string // 计数显示中的“已处理”
+}
+
+var (
+	EnglishLabels = Labels{Elapsed: "Elapsed", Remaining: "ETA", Items: "items", Processed: "processed"} // 默认
+	ChineseLabels = Labels{Elapsed: "已用", Remaining: "剩余", Items: "项", Processed: "已处理"}
+)
+
+// PercentPosition 百分比相对进度条的位置
+type PercentPosition int
+
+const (
+	PercentRight PercentPosition = iota // 0: 进度条后面(默认)
+	PercentLeft                         // 1: 进度条前面
+	PercentEnd                          // 2: 所有字段之后(后缀文字之前)
+)
+
+// Config 进度条。所有导出方法都可以在多个 goroutine 中并发调用(内部用同一把锁保护)，
+// 多个 worker 可以共享同一个进度条；例外是 SetOnRender、SetPrefixFunc 等回调，
+// 回调中只应读取状态，不要调用 Update、Add 等修改进度条的方法
 type Config struct {
+	mu sync.Mutex
+
 	current      int64
 	total        int64
-	width        int    //进度条宽度
-	showProgress bool   //是否显示进度(x/y)
-	showPercent  bool   //是否显示百分比
-	showSpeed    bool   //是否显示速度
-	showUsedTime bool   //是否显示耗时
-	showLastTime bool   //是否显示剩余时间
-	startTime    int64  //开始时间(毫秒)
-	last         int64  //计算速度用
-	lastTime     int64  //计算速度用
-	unit         Unit   // 单位
-	totalStr     string // 缓存格式化后的总数
-}
-
-// 获取终端宽度的函数
-func getTerminalWidth() int {
-	width, _, err := term.GetSize(int(os.Stdout.Fd()))
-	if err != nil {
-		width = 100
+	width        int       //进度条宽度
+	showProgress bool      //是否显示进度(x/y)
+	showPercent  bool      //是否显示百分比
+	showSpeed    bool      //是否显示速度
+	showUsedTime bool      //是否显示耗时
+	showLastTime bool      //是否显示剩余时间
+	startTime    time.Time //开始时间
+	last         int64     //计算速度用
+	lastTime     time.Time //计算速度用
+	unit         Unit      // 单位
+	totalStr     string    // 缓存格式化后的总数
+
+	out        io.Writer        // 输出目标，默认 os.Stdout
+	resizeStop chan struct{}    // 关闭以停止监听窗口大小变化
+	now        func() time.Time // 时钟，测试时可替换
+
+	showSparkline bool      // 是否显示速度走势图
+	sparkSize     int       // 走势图采样数
+	samples       []float64 // 速度采样环形缓冲
+	samplePos     int       // 下一个写入位置
+
+	leftJustify bool // 速度、剩余时间字段是否固定宽度左对齐
+
+	finished   bool      // 是否已调用 Finish
+	summaryOut io.Writer // 结束时写入纯文本汇总的目标
+
+	refreshInterval time.Duration // 两次输出的最小间隔，0 表示不限制
+	lastRender      time.Time     // 上次输出时间
+
+	showOverflow      bool // 超出总数时是否显示超出量
+	showAvgOnComplete bool // 完成时速度字段是否显示全程平均速度
+
+	counters []*Counter // 附属计数器
+
+	lastLineWidth   int  // 上一次输出的行宽，换行后归零
+	newlineOnResize bool // 终端大小变化时是否换行重绘而不是原地清除
+
+	rounding Rounding // 填充格数取整方式
+
+	color    bool   // 是否启用颜色
+	darkBg   *bool  // 终端是否为深色背景，nil 表示自动检测
+	fillSGR  string // 已完成部分的颜色，空表示自动
+	trackSGR string // 未完成部分的颜色，空表示不着色
+
+	colorProfile ColorProfile // 颜色深度
+
+	labels Labels // 输出文字
+
+	hideBar bool // 是否隐藏 [...] 进度条，仅输出文字字段
+
+	mode     Mode        // 输出方式
+	outIsTTY bool        // 输出目标是否为终端
+	fallback []io.Writer // 备选输出目标，依次选择第一个终端
+	outFile  *os.File    // SetOutputFile 打开的文件，Close 时关闭
+
+	showIterCount bool // 总数未知时是否只显示计数
+
+	onRender func(Snapshot) // 每次输出后的回调
+	snap     Snapshot       // 最近一次渲染时的状态
+	speed    float64        // 最近一次测得的瞬时速度
+	pending  []Snapshot     // 等待在锁外回调的状态
+
+	autoStop       chan struct{} // 关闭以停止自动重绘
+	heartbeatStop  chan struct{} // 关闭以停止心跳
+	onlyOnChange   bool          // 自动重绘时跳过没有进度变化的帧
+	stallClock     time.Duration // 停滞时刷新耗时的间隔，0 表示停滞时不刷新
+	paintedCurrent int64         // 上次输出时的当前值
+	paintedTotal   int64         // 上次输出时的总数
+
+	percentPos      PercentPosition // 百分比位置
+	countsBrackets  *bool           // 数量字段是否加括号，nil 表示显示百分比时才加
+	percentBrackets bool            // 百分比字段是否加括号
+
+	intrStop chan struct{} // 关闭以取消 Ctrl+C 捕获
+
+	etaMinPercent float64       // 进度低于该百分比时不显示剩余时间
+	etaMaxJump    float64       // 剩余时间相对预期值的最大变化比例，0 表示不限制
+	shownETA      time.Duration // 上次显示的剩余时间，-1 表示尚未显示
+	shownETAAt    time.Time     // 上次显示剩余时间的时刻
+	etaWindow     int           // 估算剩余时间的采样窗口大小，0 表示按全程平均速度
+	deadline      time.Time     // 截止时间，预计超出时标记剩余时间，零值表示不检查
+	etaSamples    []etaSample   // 剩余时间采样的环形缓冲区
+	etaPos        int           // 缓冲区满后下一个写入位置(即最旧的采样)
+
+	speedUnit SpeedUnit // 速度单位
+
+	done       chan struct{} // 完成或结束时关闭
+	doneClosed bool
+	err        error // Fail 记录的错误
+
+	emptyChar string // 未完成部分的字符
+
+	manualRender bool // 更新进度时不自动输出
+
+	smooth bool // 是否用 1/8 方块显示不足一格的进度
+
+	iecLabels bool // 字节后缀是否与进制一致(KiB/kB)
+
+	speedDecimals int  // 速度的小数位数
+	speedNoAlign  bool // 速度不右对齐
+
+	renderBuf []byte // RenderBytes 复用的缓冲区
+
+	label      string               // 行首说明文字
+	suffix     string               // 行尾文字
+	prefixFunc func(*Config) string // 每次渲染时计算的行首文字
+	suffixFunc func(*Config) string // 每次渲染时计算的行尾文字
+	phases     []string             // 阶段栈，显示在说明文字之后
+	rawText    bool                 // 是否原样输出说明文字和后缀(不去掉控制字符)
+
+	cfgErr   error // 设置方法累积的配置错误，见 Err
+	writeErr error // 第一次写入输出目标失败的错误，见 Wait
+
+	completeAt int64 // 视为完成的数值，0 表示使用总数
+
+	resized bool // 窗口大小已变化，下次输出前需清除旧行
+
+	headFrames []string      // 循环显示的头部字符
+	headPos    int           // 下一个头部字符的序号
+	fillDir    FillDirection // 填充方向
+	fillChar   string        // 已完成部分的字符，空表示 "="
+
+	spinner    bool            // 是否显示活动指示器
+	spinnerPos SpinnerPosition // 活动指示器的位置
+	spinFrame  int             // 下一个活动指示器字符的序号
+
+	unknownTotal string // 总数未知时计数中代替总数的文字
+
+	padLine bool // 用空格补齐整行代替 \x1b[K 清除残留字符
+
+	minSample time.Duration // 两次速度采样的最小间隔
+	sampled   bool          // 是否已有速度采样
+
+	bytesPerUnit int64 // 每项的平均字节数，用于显示换算的字节数
+
+	elapsedFmt func(time.Duration) string // 耗时的格式化函数
+	etaFmt     func(time.Duration) string // 剩余时间的格式化函数
+
+	sameUnit  bool // 字节单位下当前值使用总数的单位
+	shortTime bool // 时间使用紧凑格式
+
+	onFirstRender func() // 第一次输出前的回调
+	firstRendered bool   // 是否已输出过
+	donePainted   bool   // 是否已输出完成的最后一帧(含换行)，之后不再输出
+
+	thousandsSep rune // 数量的千位分隔符，0 表示不分隔
+
+	doneMsg   string          // 完成提示
+	doneStyle CompletionStyle // 完成提示的显示方式
+
+	widthRatio float64 // 整行占终端宽度的比例，0 表示占满
+
+	registry *registry // 登记的共享登记处，nil 表示单独输出
+
+	renderDelta int64 // 进度变化达到该值才输出，0 表示不限制
+	maxWrites   int   // 最多输出的帧数，0 表示不限制
+	writes      int   // 已输出的帧数
+	capWarned   bool  // 是否已输出达到帧数上限的警告
+
+	labelLine       bool   // 说明文字是否单独一行
+	header          string // 最近一次渲染的说明文字行
+	lastHeaderWidth int    // 上一次输出的说明文字行宽，0 表示没有该行
+
+	granularity atomic.Int64 // 增量的提交粒度
+	buffered    atomic.Int64 // 尚未提交的增量
+	untilDone   atomic.Int64 // 距完成阈值的剩余量，累计量达到时立即提交
+
+	flushInterval atomic.Int64 // 刷新间隔(纳秒)的副本，供 ProxyReader 不加锁读取
+}
+
+// 终端宽度无法获取或过小时使用的默认宽度
+const (
+	defaultWidth = 100
+	minWidth     = 20 // 小于该宽度时进度条无法正常排版
+)
+
+// 获取终端宽度的函数：输出目标是文件(如 os.Stderr)时按它的 fd 检测，否则按标准输出检测
+func getTerminalWidth(w io.Writer) int {
+	return terminalWidthOr(w, defaultWidth)
+}
+
+// 检测终端宽度，失败时返回 fallback；运行中重新检测时传入上一次的有效宽度，
+// 避免终端断开等偶发失败导致宽度突然跳回默认值
+func terminalWidthOr(w io.Writer, fallback int) int {
+	f, ok := w.(*os.File)
+	if !ok {
+		f = os.Stdout
+	}
+	width, _, err := term.GetSize(int(f.Fd()))
+	return usableWidth(width, err, fallback)
+}
+
+// 获取失败或宽度过小(部分终端、复用器会返回 1、2 之类的值)时返回 fallback
+func usableWidth(width int, err error, fallback int) int {
+	if err != nil || width < minWidth {
+		return fallback
 	}
-	// 默认返回值
 	return width
 }
 
 func ProgressBar(total int64) *Config {
 	c := &Config{
-		current:      0,
-		startTime:    time.Now().UnixNano() / int64(time.Millisecond),
-		total:        total,
-		width:        getTerminalWidth(), // 获取终端宽度
-		showProgress: true,
-		showPercent:  false,
-		showSpeed:    false,
-		last:         0,
-		lastTime:     0,
-		unit:         UnitRaw,                  // 默认单位为原始数值
-		totalStr:     fmt.Sprintf("%d", total), // 默认单位0时直接格式化
+		current:           0,
+		now:               time.Now,
+		out:               os.Stdout,
+		total:             total,
+		width:             getTerminalWidth(os.Stdout), // 获取终端宽度
+		showProgress:      true,
+		showPercent:       false,
+		showSpeed:         false,
+		showAvgOnComplete: true,
+		labels:            EnglishLabels,
+		shownETA:          -1,
+		done:              make(chan struct{}),
+		emptyChar:         " ",
+		speedDecimals:     2,
+		unknownTotal:      "?",
+		last:              0,
+		sparkSize:         defaultSparkSize,
+		unit:              UnitRaw,                  // 默认单位为原始数值
+		totalStr:          fmt.Sprintf("%d", total), // 默认单位0时直接格式化
 	}
+	c.startTime = c.now()
+	c.syncUntilDone()
+	c.outIsTTY = isTerminal(c.out)
+	c.registry = currentRegistry()
 	// 监听窗口大小变化信号（SIGWINCH）
-	sigwinch := make(chan os.Signal, 1)
-	signal.Notify(sigwinch, syscall.SIGWINCH)
-
-	go func() {
-		for {
-			select {
-			case <-sigwinch:
-				c.width = getTerminalWidth()
-			}
-		}
-	}()
+	c.watchResize()
 	return c
 }
 
+// Stderr 创建输出到 os.Stderr 的进度条，并按 stderr 检测终端宽度，
+// 适合标准输出用来传递数据、进度显示在标准错误的场景
+func Stderr(total int64) *Config {
+	return ProgressBar(total).SetOutput(os.Stderr)
+}
+
 func (c *Config) ShowProgress(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.showProgress = flag
 	return c
 }
 
 func (c *Config) ShowPercent(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.showPercent = flag
 	return c
 }
 
+// SetEmptyChar 设置进度条未完成部分的字符，如 "·" 或 "░"，让空进度条也能看出轨道，默认为空格
+func (c *Config) SetEmptyChar(s string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := validateGlyph(s); err != nil {
+		c.configError("SetEmptyChar", s, err)
+		return c
+	}
+	c.emptyChar = s
+	return c
+}
+
+// SetPercentPosition 设置百分比显示在进度条前面、紧跟进度条后面或所有字段之后
+func (c *Config) SetPercentPosition(pos PercentPosition) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.percentPos = pos
+	return c
+}
+
+// ShowOverflow 当前值超出总数时是否追加超出量标记，如 (+10)
+func (c *Config) ShowOverflow(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.showOverflow = flag
+	return c
+}
+
+// ShowSpeedAvgOnComplete 完成时速度字段是否显示全程平均速度(默认开启)
+func (c *Config) ShowSpeedAvgOnComplete(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.showAvgOnComplete = flag
+	return c
+}
+
 func (c *Config) ShowSpeed(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.showSpeed = flag
 	return c
 }
 
+// SetOutput 设置输出目标(取消 SetOutputFallback)；跟随终端宽度时按新目标重新检测宽度
+func (c *Config) SetOutput(w io.Writer) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setOutput(w)
+	return c
+}
+
+// 切换输出目标，调用方需持有锁
+func (c *Config) setOutput(w io.Writer) {
+	c.fallback = nil
+	c.out = w
+	c.outIsTTY = isTerminal(w)
+	if c.resizeStop != nil {
+		c.width = getTerminalWidth(w)
+	}
+}
+
+// SetOutputFile 以追加方式打开文件作为输出目标并切换为逐行输出(ModePlain)，
+// 适合无终端时写入日志文件再 tail -f 查看；文件在 Close 时关闭
+func (c *Config) SetOutputFile(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.outFile != nil {
+		c.outFile.Close()
+	}
+	c.outFile = f
+	c.setOutput(f)
+	c.mode = ModePlain
+	return nil
+}
+
+// SetWidth 设置固定宽度，设置后不再跟随终端大小变化
+func (c *Config) SetWidth(width int) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.width = width
+	c.stopResize()
+	return c
+}
+
+// SetBarWidthRatio 让整行只占终端宽度的一部分，如 0.5 表示一半，终端大小变化时按比例重新计算；
+// 0 或不小于 1 表示占满整行
+func (c *Config) SetBarWidthRatio(ratio float64) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.widthRatio = ratio
+	return c
+}
+
+// 进度行可用的宽度，调用方需持有锁
+func (c *Config) lineWidth() int {
+	if c.widthRatio > 0 && c.widthRatio < 1 {
+		return int(float64(c.width) * c.widthRatio)
+	}
+	return c.width
+}
+
+// SetNewlineOnResize 终端大小变化时换行后重绘(保留旧行)，默认原地清除旧行后重绘
+func (c *Config) SetNewlineOnResize(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.newlineOnResize = flag
+	return c
+}
+
+// SetPadToWidth 原地刷新时用空格把每行补齐到终端宽度(保留最后一列)来覆盖旧行残留的字符，
+// 代替默认的 \x1b[K 清除到行尾，两种方式只会使用其中一种
+func (c *Config) SetPadToWidth(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.padLine = flag
+	return c
+}
+
+// SetRounding 设置进度条填充格数的取整方式
+func (c *Config) SetRounding(mode Rounding) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rounding = mode
+	return c
+}
+
+// 按取整方式计算填充格数
+func (c *Config) roundCells(cells float64) int {
+	switch c.rounding {
+	case RoundNearest:
+		return int(math.Round(cells))
+	case RoundCeil:
+		return int(math.Ceil(cells))
+	}
+	return int(cells)
+}
+
+// SetCountsBrackets 数量字段(x/y)是否加括号，设置后不再随百分比是否显示而变化；
+// 未设置时保持原来的规则：显示百分比时加括号，否则不加
+func (c *Config) SetCountsBrackets(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.countsBrackets = &flag
+	return c
+}
+
+// SetPercentBrackets 百分比字段是否加括号，如 (42.0%)，默认不加
+func (c *Config) SetPercentBrackets(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.percentBrackets = flag
+	return c
+}
+
+// 数量字段是否加括号
+func (c *Config) countsInBrackets() bool {
+	if c.countsBrackets != nil {
+		return *c.countsBrackets
+	}
+	return c.showPercent
+}
+
+// SetShowBar 是否显示 [...] 进度条，关闭后只输出百分比、数量、速度等文字字段
+func (c *Config) SetShowBar(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hideBar = !flag
+	return c
+}
+
+// SetShowIterationCount 总数未知(<=0)时改为只显示已处理数量、速度和耗时，如
+// "1234 items processed (56.00 items/s) 00:00:22"
+func (c *Config) SetShowIterationCount(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.showIterCount = flag
+	return c
+}
+
+// SetLabels 设置耗时、剩余时间和速度单位的文字，默认为 EnglishLabels
+func (c *Config) SetLabels(labels Labels) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.labels = labels
+	return c
+}
+
+// SetLeftJustify 将速度和剩余时间字段填充到固定宽度并左对齐，避免数值变化时整行左右抖动
+func (c *Config) SetLeftJustify(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.leftJustify = flag
+	return c
+}
+
+// SetRenderOnUpdate 更新进度(Update/Add/Increment 等)时是否自动输出，默认开启；
+// 关闭后这些方法只记录状态，由调用方自行调用 Draw 或依赖自动重绘
+func (c *Config) SetRenderOnUpdate(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.manualRender = !flag
+	return c
+}
+
+// SetRefreshInterval 设置两次输出之间的最小间隔，0 或负数表示不限制
+func (c *Config) SetRefreshInterval(d time.Duration) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d < 0 {
+		d = 0
+	}
+	c.refreshInterval = d
+	c.flushInterval.Store(int64(d))
+	return c
+}
+
+// SetRefreshFPS 按每秒帧数设置刷新频率，0 或负数表示不限制
+func (c *Config) SetRefreshFPS(fps int) *Config {
+	if fps <= 0 {
+		return c.SetRefreshInterval(0)
+	}
+	return c.SetRefreshInterval(time.Second / time.Duration(fps))
+}
+
 func (c *Config) SetUnit(unit Unit) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.unit = unit
-	// 一次性计算完成，不关心后续变动
-	if unit == UnitBytes {
-		c.totalStr = formatBytes(c.total)
-	} else {
-		c.totalStr = fmt.Sprintf("%d", c.total)
+	c.updateTotalStr()
+	return c
+}
+
+// SetTotalUnknownText 设置总数未知(<=0)时计数中代替总数的文字，默认 "?"，显示为 420/?
+func (c *Config) SetTotalUnknownText(s string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unknownTotal = s
+	return c
+}
+
+// SetBytesPerUnit 按数量计数时，按每项的平均字节数在计数后追加换算的字节数，
+// 如 420/1000 (~4.1 KB/9.8 KB)；0 表示不显示，字节单位下无效
+func (c *Config) SetBytesPerUnit(n int64) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	c.bytesPerUnit = n
+	return c
+}
+
+// SetSameUnit 字节单位下当前值和总数统一使用总数的单位，如 0.01/100.00 MB，
+// 避免当前值的单位随大小变化(B→KB→MB)导致字段宽度跳动，默认关闭
+func (c *Config) SetSameUnit(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sameUnit = flag
+	return c
+}
+
+// SetThousandsSeparator 按数量显示时每三位插入分隔符，如 ',' 显示为 1,234,567/9,999,999；
+// 0 表示不分隔(默认)
+func (c *Config) SetThousandsSeparator(sep rune) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.thousandsSep = sep
+	c.updateTotalStr()
+	return c
+}
+
+// 按设置格式化数量
+func (c *Config) countStr(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	if c.thousandsSep == 0 {
+		return s
+	}
+	sign := ""
+	if n < 0 {
+		sign, s = "-", s[1:]
+	}
+	var b strings.Builder
+	for i, d := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteRune(c.thousandsSep)
+		}
+		b.WriteRune(d)
 	}
+	return sign + b.String()
+}
+
+// SetIECLabels 字节后缀是否与进制一致：开启后 1024 进制显示 KiB/MiB，1000 进制显示 kB/MB；
+// 默认关闭，统一显示 KB/MB
+func (c *Config) SetIECLabels(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.iecLabels = flag
+	c.updateTotalStr()
 	return c
 }
 
+// 重新计算缓存的总数字符串，调用方需持有锁
+func (c *Config) updateTotalStr() {
+	if c.unit == UnitBytes {
+		c.totalStr = c.sizeStr(c.total)
+	} else {
+		c.totalStr = c.countStr(c.total)
+	}
+}
+
+// SetTotal 修改总数并输出，适合开始时总数未知、途中才得知的场景；0 或负数表示未知
+func (c *Config) SetTotal(total int64) {
+	c.mu.Lock()
+	defer c.unlock()
+	if c.finished {
+		return
+	}
+	// 未提交的增量属于修改总数之前的进度，先提交
+	c.current += c.buffered.Swap(0)
+	c.total = total
+	c.updateTotalStr()
+	c.changed()
+}
+
+// Update 将当前值设置为绝对值 current 并输出，可以回退，负数按 0 处理
 func (c *Config) Update(current int64) {
-	if current > c.current && current <= c.total {
-		c.current = current
+	c.mu.Lock()
+	defer c.unlock()
+	if c.finished {
+		return
+	}
+	if current < 0 {
+		current = 0
+	}
+	c.buffered.Store(0) // 绝对值已包含未提交的增量
+	c.current = current
+	c.changed()
+}
+
+// Add 在当前值基础上增加 delta 并输出
+func (c *Config) Add(delta int64) {
+	// 设置了更新粒度时先在锁外累计，达到粒度或完成阈值才加锁提交，保证进度条能自行完成
+	buffered := false
+	if g := c.granularity.Load(); g > 1 && delta > 0 {
+		if n := c.buffered.Add(delta); n < g && n < c.untilDone.Load() {
+			return
+		}
+		buffered = true
+	}
+	c.mu.Lock()
+	defer c.unlock()
+	if c.finished {
+		return
+	}
+	if buffered {
+		// 在锁内取出累计量，与 Update 清零累计量互斥
+		if delta = c.buffered.Swap(0); delta == 0 {
+			return
+		}
+	}
+	c.current += delta
+	if c.current < 0 {
+		c.current = 0
 	}
-	c.ShowProgressBar()
+	c.changed()
 }
 
+// SetUpdateGranularity 设置 Add/Increment 的提交粒度：增量先在锁外累计，满 n 才提交到进度条，
+// 减少逐字节读取等场景下的锁竞争；累计量达到完成阈值(总数或 SetCompleteAt)时立即提交，
+// 其余不足 n 的余量在 Finish 时提交，Update 设置绝对值时丢弃。0 或 1 表示每次都提交
+func (c *Config) SetUpdateGranularity(n int64) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.granularity.Store(n)
+	c.current += c.buffered.Swap(0)
+	c.syncUntilDone()
+	return c
+}
+
+// 记录距完成阈值的剩余量，供 Add 的快速路径判断何时必须立即提交，调用方需持有锁
+func (c *Config) syncUntilDone() {
+	limit := c.completeAt
+	if limit <= 0 {
+		limit = c.total
+	}
+	if limit <= 0 {
+		c.untilDone.Store(math.MaxInt64)
+		return
+	}
+	c.untilDone.Store(limit - c.current)
+}
+
+// Increment 等同于 Add(1)
 func (c *Config) Increment() {
-	if c.current < c.total {
-		c.current++
+	c.Add(1)
+}
+
+// Write 实现 io.Writer，按写入的字节数推进进度，数据本身被丢弃；
+// 进度条本身只输出到 SetOutput 设置的目标，因此 io.Copy(pb, src) 不会把数据写到终端
+func (c *Config) Write(p []byte) (int, error) {
+	c.Add(int64(len(p)))
+	return len(p), nil
+}
+
+// WriteString 实现 io.StringWriter
+func (c *Config) WriteString(s string) (int, error) {
+	c.Add(int64(len(s)))
+	return len(s), nil
+}
+
+// Done 返回一个在达到总数或调用 Finish/Fail 时关闭的 channel
+func (c *Config) Done() <-chan struct{} {
+	return c.done
+}
+
+// Wait 阻塞直到达到总数或调用 Finish/Fail，返回 Fail 记录的错误，
+// 没有时返回输出过程中第一次写入失败的错误
+func (c *Config) Wait() error {
+	<-c.done
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	return c.writeErr
+}
+
+// 关闭 done，只关闭一次，调用方需持有锁
+func (c *Config) closeDone() {
+	if !c.doneClosed {
+		c.doneClosed = true
+		close(c.done)
+	}
+}
+
+// IsComplete 是否已达到总数
+func (c *Config) IsComplete() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.complete()
+}
+
+// IsStarted 是否已有进度
+func (c *Config) IsStarted() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.current > 0
+}
+
+// Elapsed 返回从开始到现在的耗时
+func (c *Config) Elapsed() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now().Sub(c.startTime)
+}
+
+// SetStartTime 覆盖构造时记录的开始时间，配合 Update 恢复的进度，
+// 重启后的耗时和剩余时间仍按最初开始的时刻计算
+func (c *Config) SetStartTime(t time.Time) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.startTime = t
+	return c
+}
+
+// ETA 返回估算的剩余时间，与显示的值一致：尚无进度、无法估算或低于 SetETAClamp 的最小百分比时返回 -1 和 false；
+// 设置了最大变化比例时按上次显示的值限制，但调用本方法不会改变之后显示的值
+func (c *Config) ETA() (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	s := c.snapshot(now)
+	eta, ok := c.clampETA(now, s.Percent, s.ETA)
+	if !ok {
+		return -1, false
+	}
+	return eta, true
+}
+
+// SetSummaryWriter 设置结束时写入汇总行的目标(不含控制字符，适合日志文件)
+func (c *Config) SetSummaryWriter(w io.Writer) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.summaryOut = w
+	return c
+}
+
+// CompletionStyle 完成提示的显示方式
+type CompletionStyle int
+
+const (
+	CompletionKeep    CompletionStyle = iota // 0: 保留完成的进度条，提示输出在下一行(默认)
+	CompletionReplace                        // 1: 用提示替换进度条所在的行
+)
+
+// SetCompletionMessage 设置正常结束(达到总数或 Finish)时输出的提示，如 "完成!"，
+// 空字符串表示不输出；Fail 结束时不输出
+func (c *Config) SetCompletionMessage(msg string, style CompletionStyle) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.doneMsg = msg
+	c.doneStyle = style
+	return c
+}
+
+// Finish 结束进度条：未完成时补画最后一帧并换行，然后写出汇总，重复调用无效果。
+// 结束(Finish/Fail/Close)后进度条即被封存，之后的 Update/Add 等更新都不再生效也不再输出，
+// 迟到的 worker 不会在后续输出上再画出进度条
+func (c *Config) Finish() {
+	c.mu.Lock()
+	defer c.unlock()
+	c.finish(nil)
+}
+
+// Fail 以错误结束进度条：保留当前进度并换行，记录错误，其余同 Finish
+func (c *Config) Fail(err error) {
+	c.mu.Lock()
+	defer c.unlock()
+	c.finish(err)
+}
+
+// Close 实现 io.Closer：结束进度条(同 Finish)并停止所有后台 goroutine，
+// 适合 defer pb.Close()，无论是否已完成、是否开始过都可以安全调用；
+// 使用 SetOutputFile 时同时关闭文件；返回关闭文件的错误和 Err 中的配置错误
+func (c *Config) Close() error {
+	c.Finish()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.outFile == nil {
+		return c.cfgErr
+	}
+	err := c.outFile.Close()
+	c.outFile = nil
+	return errors.Join(c.cfgErr, err)
+}
+
+// 结束进度条，调用方需持有锁
+func (c *Config) finish(err error) {
+	if c.finished {
+		return
+	}
+	c.finished = true
+	c.err = err
+	c.current += c.buffered.Swap(0)
+	defer c.closeDone()
+	c.stopAutoRender()
+	c.stopHeartbeat()
+	c.stopInterrupt()
+	c.stopResize()
+	if !c.donePainted {
+		c.emit(c.render(), true)
+	}
+	if c.summaryOut != nil {
+		fmt.Fprintln(c.summaryOut, c.summary())
+	}
+}
+
+// 生成汇总行：数量、耗时、平均速度
+func (c *Config) summary() string {
+	usedTime := c.now().Sub(c.startTime)
+	var avg float64
+	if usedTime > 0 {
+		avg = float64(c.current) / usedTime.Seconds()
 	}
-	c.ShowProgressBar()
+	if c.unit == UnitBytes {
+		return fmt.Sprintf("%s/%s in %s, avg %s/s",
+			strings.TrimSpace(c.sizeStr(c.current)), strings.TrimSpace(c.totalStr),
+			formatTime(usedTime), strings.TrimSpace(c.sizeStr(int64(avg))))
+	}
+	return fmt.Sprintf("%d/%d in %s, avg %.2f %s/s", c.current, c.total, formatTime(usedTime), avg, c.labels.Items)
 }
 
 func (c *Config) ShowProgressBar() {
-	// 计算进度百分比
-	var percent float64
-	if c.total > 0 {
-		percent = float64(c.current) / float64(c.total) * 100
+	c.mu.Lock()
+	defer c.unlock()
+	c.maybeRender(false)
+}
+
+// Draw 立即输出一帧，不受刷新间隔限制，仍遵循输出方式等设置；结束后调用无效果
+func (c *Config) Draw() {
+	c.mu.Lock()
+	defer c.unlock()
+	if c.finished {
+		return
+	}
+	c.maybeRender(true)
+}
+
+// 状态变化后调用：更新完成状态，并按设置输出，调用方需持有锁
+func (c *Config) changed() {
+	c.syncUntilDone()
+	if c.complete() {
+		c.closeDone()
+	}
+	if !c.manualRender {
+		c.maybeRender(false)
+	}
+}
+
+// 立即输出一帧，调用方需持有锁
+func (c *Config) paint() {
+	c.clearResized()
+	c.paintedCurrent, c.paintedTotal = c.current, c.total
+	c.emit(c.render(), c.complete())
+}
+
+// 输出一行：交互模式下用 \r 原地覆盖，完成时换行；普通模式下每次输出完整的一行
+func (c *Config) emit(line string, done bool) {
+	if !c.firstRendered {
+		c.firstRendered = true
+		if c.onFirstRender != nil {
+			c.onFirstRender()
+		}
+	}
+	if c.onRender != nil {
+		c.pending = append(c.pending, c.snap)
+	}
+	if done {
+		c.donePainted = true
+	}
+	// 结束时的提示：替换模式下代替最后一行进度条，否则在进度条下一行输出
+	message := ""
+	if done && c.err == nil && c.doneMsg != "" {
+		if c.doneStyle == CompletionReplace {
+			line = c.doneMsg
+		} else {
+			message = c.doneMsg
+		}
+	}
+	header := c.header
+	// 兜底：无论前面的宽度计算是否准确，都不允许超过终端宽度而折行
+	if width := c.lineWidth(); width > 1 {
+		line = truncateToWidth(line, width-1)
+		header = truncateToWidth(header, width-1)
+	}
+	// 逐行输出的约定：不输出 \r，每次输出(包括最后一行)都是以 \n 结尾的完整一行
+	if c.plain() {
+		if header != "" {
+			c.write(strings.ReplaceAll(header, "\r", "") + "\n")
+		}
+		c.write(strings.ReplaceAll(line, "\r", "") + "\n")
+		c.lastLineWidth = 0
+		c.printMessage(message)
+		return
+	}
+	// 登记到共享登记处的进度条由登记处统一排列输出
+	if c.registry != nil {
+		c.noteWriteErr(c.registry.update(c, c.out, joinText(header, line), done))
+		c.lastLineWidth = 0
+		c.printMessage(message)
+		return
+	}
+	// 新行比旧行短时清除旧行残留的字符：默认用 \x1b[K 清除到行尾，
+	// SetPadToWidth 开启时改为用空格补齐整行，适合不支持该控制序列的终端
+	width := displayWidth(line)
+	if c.padLine && c.lineWidth() > 1 {
+		line = padToWidth(line, c.lineWidth()-1)
+		width = displayWidth(line)
+	} else if width < c.lastLineWidth {
+		line += "\x1b[K"
+	}
+	c.emitHeader(header)
+	c.write("\r" + line)
+	c.lastLineWidth = width
+	if done {
+		c.write("\n")
+		c.lastLineWidth = 0
+		c.lastHeaderWidth = 0
+		c.printMessage(message)
+	}
+}
+
+// 原地刷新时输出进度条上方的说明文字行：光标先回到上次的说明文字行，覆盖后换到进度条所在的行
+func (c *Config) emitHeader(header string) {
+	if c.lastHeaderWidth > 0 {
+		c.write("\x1b[1A")
+	}
+	if header == "" {
+		if c.lastHeaderWidth > 0 {
+			// 说明文字被清空，删除原来的行
+			c.write("\r\x1b[M")
+		}
+		c.lastHeaderWidth = 0
+		return
+	}
+	c.write("\r" + header + "\x1b[K\n")
+	c.lastHeaderWidth = max(displayWidth(header), 1)
+}
+
+// 写入输出目标，记录第一次写入错误供 Wait 返回，调用方需持有锁
+func (c *Config) write(s string) {
+	_, err := io.WriteString(c.out, s)
+	c.noteWriteErr(err)
+}
+
+// 记录第一次写入错误，调用方需持有锁
+func (c *Config) noteWriteErr(err error) {
+	if err != nil && c.writeErr == nil {
+		c.writeErr = err
+	}
+}
+
+// 在单独的一行输出提示，空字符串时不输出
+func (c *Config) printMessage(msg string) {
+	if msg != "" {
+		c.write(msg + "\n")
+	}
+}
+
+// 终端宽度变化后更新宽度，并经统一入口重绘；旧行留到真正重绘时再清除，
+// 这样限流期间的多次变化只会清除、重绘一次，调用方需持有锁
+func (c *Config) resize(width int) {
+	c.width = width
+	if c.lastLineWidth == 0 || c.finished {
+		return
+	}
+	c.resized = true
+	c.maybeRender(false)
+}
+
+// 如有待处理的窗口变化，在新宽度下清除上一次输出的整行，调用方需持有锁
+func (c *Config) clearResized() {
+	if !c.resized {
+		return
+	}
+	c.resized = false
+	if c.lastLineWidth == 0 {
+		return
+	}
+	if c.newlineOnResize {
+		c.write("\n")
+	} else {
+		// 旧行比新宽度长时已被终端折成多行，先回到第一行再清除；
+		// 说明文字单独一行时还要再往上回到说明文字所在的行
+		if c.width > 0 {
+			rows := (c.lastLineWidth - 1) / c.width
+			if c.lastHeaderWidth > 0 {
+				rows += 1 + (c.lastHeaderWidth-1)/c.width
+			}
+			if rows > 0 {
+				c.write(fmt.Sprintf("\x1b[%dA", rows))
+			}
+		}
+		c.write("\r\x1b[J")
+	}
+	c.lastLineWidth = 0
+	c.lastHeaderWidth = 0
+}
+
+// 计算瞬时速度并记录采样；结束时按设置返回全程平均速度。
+// 无论是否显示速度都会更新基准，中途开启速度显示时立即得到正确的值
+func (c *Config) sampleSpeed(now time.Time) (float64, bool) {
+	var speed float64
+	hasSpeed := false
+	duration := now.Sub(c.lastTime)
+	switch {
+	case c.lastTime.IsZero():
+		c.last, c.lastTime = c.current, now
+	case duration <= 0 || duration < c.minSample:
+		// 间隔不足最小采样间隔时继续累积，沿用上一次的速度，避免除以极小的时间差
+		speed, hasSpeed = c.speed, c.sampled
+	default:
+		speed = float64(c.current-c.last) / duration.Seconds()
+		hasSpeed = true
+		c.speed = speed
+		c.sampled = true
+		c.pushSample(speed)
+		c.last, c.lastTime = c.current, now
+	}
+	// 结束时显示全程平均速度，而不是最后一次的瞬时采样
+	usedTime := now.Sub(c.startTime)
+	if c.showAvgOnComplete && (c.finished || c.complete()) && usedTime > 0 {
+		speed = float64(c.current) / usedTime.Seconds()
+		hasSpeed = true
+	}
+	return speed, hasSpeed
+}
+
+// 是否已完成：达到 SetCompleteAt 设置的值，未设置时为达到总数；
+// 总数未知(<=0)且未设置时只能通过 Finish 结束
+func (c *Config) complete() bool {
+	if c.completeAt > 0 {
+		return c.current >= c.completeAt
+	}
+	return c.total > 0 && c.current >= c.total
+}
+
+// SetCompleteAt 设置视为完成(输出换行、关闭 Done)的数值，0 或负数表示使用总数；
+// 完成行只输出一次，之后继续 Update/Add 只累计进度，不再输出
+func (c *Config) SetCompleteAt(n int64) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	c.completeAt = n
+	c.syncUntilDone()
+	return c
+}
+
+// 计数显示：已处理数量、速度、耗时，不含进度条和百分比
+func (c *Config) renderCounter(usedTime time.Duration, speed float64, hasSpeed bool) string {
+	var output string
+	if c.unit == UnitBytes {
+		output = fmt.Sprintf("%s %s", strings.TrimSpace(c.sizeStr(c.current)), c.labels.Processed)
+	} else {
+		output = fmt.Sprintf("%s %s %s", c.countStr(c.current), c.labels.Items, c.labels.Processed)
 	}
+	if c.showSpeed && hasSpeed {
+		output += fmt.Sprintf(" (%s)", strings.TrimSpace(c.formatSpeed(speed)))
+	}
+	if c.showUsedTime {
+		output += " " + c.elapsedStr(usedTime)
+	}
+	return output
+}
+
+// Render 生成当前进度行(不含回车与换行)
+func (c *Config) Render() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.render()
+}
+
+// RenderBytes 与 Render 相同，但以字节切片返回；切片复用内部缓冲区，
+// 下次调用 RenderBytes 后内容会被覆盖，需要保留时请自行复制
+func (c *Config) RenderBytes() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.renderBuf = append(c.renderBuf[:0], c.render()...)
+	return c.renderBuf
+}
+
+// RenderLines 生成当前进度的各行(不含回车与换行)：说明文字单独一行(SetLabelOnOwnLine)时
+// 第一行为说明文字，第二行为进度条和各字段，否则只有一行
+func (c *Config) RenderLines() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	line := c.render()
+	if c.header != "" {
+		return []string{c.header, line}
+	}
+	return []string{line}
+}
+
+// SetLabelOnOwnLine 将说明文字(SetLabel、阶段、SetPrefixFunc)放在进度条上方单独的一行，
+// 原地刷新时两行一起重绘
+func (c *Config) SetLabelOnOwnLine(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.labelLine = flag
+	return c
+}
 
-	// 计算时间相关数据
-	currentTime := time.Now().UnixNano() / int64(time.Millisecond)
-	usedTime := currentTime - c.startTime // 已用时间(毫秒)
-	var lastTime int64
-	if percent > 0 {
-		lastTime = int64(float64(usedTime)*(100/percent) - float64(usedTime))
+// 生成进度行，调用方需持有锁
+func (c *Config) render() string {
+	// 先计算装饰文字：动态函数会暂时释放锁，之后读取的状态保持一致
+	decoPrefix, decoSuffix := c.decorations()
+	// 说明文字单独一行时不放在进度条前面，由 renderLines 放在第一行
+	c.header = ""
+	if c.labelLine {
+		c.header, decoPrefix = strings.TrimSpace(decoPrefix), ""
 	}
-	if c.total > 0 {
-		percent = float64(c.current) / float64(c.total) * 100
+	now := c.now()
+	speed, hasSpeed := c.sampleSpeed(now)
+	c.pushETASample(now)
+	c.snap = c.snapshot(now)
+	percent := c.snap.Percent
+	usedTime := c.snap.Elapsed // 已用时间
+	lastTime := c.snap.ETA     // 剩余时间
+
+	// 总数未知时只显示计数
+	if c.showIterCount && c.total <= 0 {
+		return decoPrefix + c.renderCounter(usedTime, speed, hasSpeed) + decoSuffix
 	}
 
-	// 格式化当前数值
+	// 格式化当前数值，总数未知时用占位文字代替总数
+	totalStr := c.totalStr
+	if c.total <= 0 {
+		totalStr = c.unknownTotal
+	}
 	var currentStr string
 	if c.unit == UnitBytes {
-		currentStr = formatBytes(c.current)
+		currentStr = c.sizeStr(c.current)
+	} else if c.total <= 0 {
+		currentStr = c.countStr(c.current)
 	} else {
-		currentStrLength := len(c.totalStr)
-		format := fmt.Sprintf("%%%dd", currentStrLength)
-		currentStr = fmt.Sprintf(format, c.current)
+		// 按总数的显示宽度右对齐，分隔符也计入宽度
+		currentStr = c.countStr(c.current)
+		if pad := displayWidth(c.totalStr) - displayWidth(currentStr); pad > 0 {
+			currentStr = strings.Repeat(" ", pad) + currentStr
+		}
 	}
 
 	output := ""
 
-	// 添加百分比(紧跟在进度条后面)
+	// 添加百分比(默认紧跟在进度条后面，也可以放在进度条前面或所有字段之后)
+	prefix, percentStr := "", ""
 	if c.showPercent {
-		output += fmt.Sprintf(" %.1f%%", percent)
+		percentStr = fmt.Sprintf("%.1f%%", percent)
+		if c.percentBrackets {
+			percentStr = "(" + percentStr + ")"
+		}
+		switch c.percentPos {
+		case PercentLeft:
+			prefix = percentStr + " "
+		case PercentRight:
+			output += " " + percentStr
+		}
 	}
 
 	// 添加进度(x/y) - 可独立控制
 	if c.showProgress {
-		if c.showPercent {
-			output += fmt.Sprintf(" (%s/%s)", currentStr, c.totalStr)
+		counts := currentStr + "/" + totalStr
+		if c.unit == UnitBytes && c.sameUnit && c.total > 0 {
+			counts = c.sameUnitCounts()
+		}
+		if c.countsInBrackets() {
+			counts = "(" + counts + ")"
+		}
+		output += " " + counts
+	}
+
+	// 添加按每项字节数换算的等量字节
+	if c.bytesPerUnit > 0 && c.unit == UnitRaw {
+		equiv := "~" + strings.TrimSpace(c.sizeStr(c.current*c.bytesPerUnit))
+		if c.total > 0 {
+			equiv += "/" + strings.TrimSpace(c.sizeStr(c.total*c.bytesPerUnit))
+		}
+		output += " (" + equiv + ")"
+	}
+
+	// 添加附属计数器
+	for _, s := range c.counters {
+		output += " " + s.String()
+	}
+
+	// 添加超出标记
+	if c.showOverflow && c.total > 0 && c.current > c.total {
+		over := c.current - c.total
+		if c.unit == UnitBytes {
+			output += fmt.Sprintf(" (+%s)", strings.TrimSpace(c.sizeStr(over)))
 		} else {
-			output += fmt.Sprintf(" %s/%s", currentStr, c.totalStr)
+			output += fmt.Sprintf(" (+%d)", over)
 		}
 	}
 
 	// 添加速度
 	if c.showSpeed {
-		now := time.Now().UnixNano() / int64(time.Millisecond)
-		if c.lastTime > 0 {
-			duration := now - c.lastTime
-			if duration > 0 {
-				speed := float64(c.current-c.last) / (float64(duration) / 1000.0)
-				if c.unit == UnitBytes {
-					speedBytes := int64(speed * 1024) // 将KB/s转换为B/s
-					output += fmt.Sprintf(" (%s/s)", formatBytes(speedBytes))
-				} else {
-					output += fmt.Sprintf(" (%7.2f items/s)", speed)
-				}
-			}
+		speedStr := ""
+		if hasSpeed {
+			speedStr = " (" + c.formatSpeed(speed) + ")"
+		}
+		if c.leftJustify {
+			speedStr = padToWidth(speedStr, c.speedFieldWidth())
 		}
-		c.last = c.current
-		c.lastTime = now
+		output += speedStr
+	}
+
+	// 添加速度走势图
+	if c.showSparkline && len(c.samples) > 0 {
+		output += " " + c.sparkline()
 	}
 
 	// 添加时间信息
-	if c.showUsedTime && c.showLastTime && percent > 0 {
-		output += fmt.Sprintf(" [%s/%s]", formatTime(usedTime), formatTime(lastTime))
+	// 固定宽度模式下剩余时间未知时用占位符，避免字段出现时整行跳动
+	lastTimeStr := ""
+	if eta, ok := c.displayETA(now, percent, lastTime); ok {
+		lastTimeStr = c.markDeadline(now, eta, c.etaStr(eta))
+	} else if percent > 0 || c.leftJustify {
+		lastTimeStr = "--:--:--"
+	}
+	if c.showUsedTime && c.showLastTime && lastTimeStr != "" {
+		output += fmt.Sprintf(" [%s/%s]", c.elapsedStr(usedTime), lastTimeStr)
 	} else {
 		if c.showUsedTime {
-			output += fmt.Sprintf(" [已用:%s]", formatTime(usedTime))
+			output += fmt.Sprintf(" [%s:%s]", c.labels.Elapsed, c.elapsedStr(usedTime))
 		}
-		if c.showLastTime && percent > 0 {
-			output += fmt.Sprintf(" [剩余:%s]", formatTime(lastTime))
+		if c.showLastTime && lastTimeStr != "" {
+			output += fmt.Sprintf(" [%s:%s]", c.labels.Remaining, lastTimeStr)
 		}
 	}
-	// 计算进度条长度
-	progressWidth := c.width - len(output) - 2
-	progressLength := int(float64(progressWidth) * percent / 100)
+	if c.showPercent && c.percentPos == PercentEnd {
+		output += " " + percentStr
+	}
+	output += decoSuffix
+	prefix = decoPrefix + prefix
 
-	// 构建进度条字符串
-	bar := ""
-	for i := 0; i < progressWidth; i++ {
-		if i < progressLength {
-			bar += "="
-		} else if i == progressLength && progressLength < progressWidth {
-			bar += ">"
+	// 活动指示器占用一列，计入下面的进度条宽度计算
+	if spin := c.nextSpinner(); spin != "" {
+		if c.spinnerPos == SpinnerBefore {
+			prefix += spin + " "
 		} else {
-			bar += " "
+			output += " " + spin
 		}
 	}
 
+	// 不显示进度条时只输出各字段
+	if c.hideBar {
+		return strings.TrimSpace(prefix + strings.TrimPrefix(output, " "))
+	}
+
+	// 计算进度条长度
+	// 保留最后一列，避免写满整行时终端自动折行
+	progressWidth := c.lineWidth() - 1 - displayWidth(prefix) - displayWidth(output) - 2
+
+	// 构建进度条字符串
+	var bar string
+	switch c.fillDir {
+	case Center:
+		left, filled, right := c.centerCells(progressWidth, percent)
+		bar = c.colorize(left, c.trackSGR) + c.colorize(filled, c.fillColor()) + c.colorize(right, c.trackSGR)
+	case RightToLeft:
+		filled, empty := c.barCells(progressWidth, percent)
+		bar = c.colorize(empty, c.trackSGR) + c.colorize(reverseRunes(filled), c.fillColor())
+	default:
+		filled, empty := c.barCells(progressWidth, percent)
+		bar = c.colorize(filled, c.fillColor()) + c.colorize(empty, c.trackSGR)
+	}
+
 	// 构建输出字符串
-	output = "\r[" + bar + "]" + output
+	return prefix + "[" + bar + "]" + output
+}
+
+// SetElapsedFormat 设置耗时的格式化函数，如 time.Duration.String，nil 表示默认的 时:分:秒
+func (c *Config) SetElapsedFormat(fn func(time.Duration) string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.elapsedFmt = fn
+	return c
+}
+
+// SetETAFormat 设置剩余时间的格式化函数，nil 表示默认的 时:分:秒
+func (c *Config) SetETAFormat(fn func(time.Duration) string) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.etaFmt = fn
+	return c
+}
+
+// SetAbbreviatedTime 耗时和剩余时间使用紧凑格式，省略为零的高位，如 65s、1m5s、1h2m5s；
+// 默认为 时:分:秒，SetElapsedFormat/SetETAFormat 设置的函数优先
+func (c *Config) SetAbbreviatedTime(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.shortTime = flag
+	return c
+}
+
+// 按设置格式化耗时
+func (c *Config) elapsedStr(d time.Duration) string {
+	if c.elapsedFmt != nil {
+		return c.elapsedFmt(d)
+	}
+	return c.timeStr(d)
+}
+
+// 按设置格式化剩余时间
+func (c *Config) etaStr(d time.Duration) string {
+	if c.etaFmt != nil {
+		return c.etaFmt(d)
+	}
+	return c.timeStr(d)
+}
 
-	// 输出进度条
-	fmt.Print(output)
+// 按默认或紧凑格式格式化时间
+func (c *Config) timeStr(d time.Duration) string {
+	if c.shortTime {
+		return formatShortTime(d)
+	}
+	return formatTime(d)
+}
 
-	// 如果完成，则换行
-	if c.current >= c.total {
-		fmt.Println()
+// 辅助函数：紧凑格式的时间，精确到秒，省略为零的高位
+func formatShortTime(d time.Duration) string {
+	seconds := int64(d / time.Second)
+	hours, minutes := seconds/3600, seconds%3600/60
+	seconds %= 60
+	switch {
+	case hours > 0:
+		return fmt.Sprintf("%dh%dm%ds", hours, minutes, seconds)
+	case minutes > 0:
+		return fmt.Sprintf("%dm%ds", minutes, seconds)
 	}
+	return fmt.Sprintf("%ds", seconds)
 }
 
-// 辅助函数：格式化时间(毫秒转为 时:分:秒)
-func formatTime(ms int64) string {
-	seconds := ms / 1000
+// 辅助函数：格式化时间(时:分:秒)
+func formatTime(d time.Duration) string {
+	seconds := int64(d / time.Second)
 	hours := seconds / 3600
 	seconds = seconds % 3600
 	minutes := seconds / 60
@@ -227,23 +1416,63 @@ func formatTime(ms int64) string {
 
 // 辅助函数：将字节数转换为友好格式
 func formatBytes(bytes int64) string {
-	const unit = 1024
+	return formatSize(bytes, 1024, false)
+}
+
+// 辅助函数：按指定进制(1024 或 1000)将字节数转换为友好格式；
+// iec 为 true 时后缀与进制一致：1024 进制用 KiB/MiB，1000 进制用 kB/MB，否则统一用 KB/MB
+func formatSize(bytes int64, unit int64, iec bool) string {
 	if bytes < unit {
 		return fmt.Sprintf("%3d B", bytes)
 	}
+	div, suffix := sizeScale(bytes, unit, iec)
+	return fmt.Sprintf("%6.1f %s", float64(bytes)/float64(div), suffix)
+}
+
+// 辅助函数：字节数应使用的除数和后缀，如 (1048576, "MB")，不足 unit 时为 (1, "B")
+func sizeScale(bytes int64, unit int64, iec bool) (int64, string) {
+	if bytes < unit {
+		return 1, "B"
+	}
+	// 后缀最多到 E，超出时停在最后一个后缀上，避免越界
+	const suffixes = "KMGTPE"
 	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
+	for n := bytes / unit; n >= unit && exp < len(suffixes)-1; n /= unit {
 		div *= unit
 		exp++
 	}
-	return fmt.Sprintf("%6.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+	prefix := string(suffixes[exp])
+	if iec {
+		if unit == 1024 {
+			prefix += "i"
+		} else if exp == 0 {
+			prefix = "k"
+		}
+	}
+	return div, prefix + "B"
+}
+
+// 以总数的单位同时显示当前值和总数，如 "  0.01/100.00 MB"，当前值按总数的宽度右对齐
+func (c *Config) sameUnitCounts() string {
+	div, suffix := sizeScale(c.total, 1024, c.iecLabels)
+	totalNum := fmt.Sprintf("%.2f", float64(c.total)/float64(div))
+	return fmt.Sprintf("%*.2f/%s %s", len(totalNum), float64(c.current)/float64(div), totalNum, suffix)
+}
+
+// 按当前后缀设置格式化 1024 进制的字节数
+func (c *Config) sizeStr(bytes int64) string {
+	return formatSize(bytes, 1024, c.iecLabels)
 }
 
 func (c *Config) ShowUsedTime(flag bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.showUsedTime = flag
 }
 
 func (c *Config) ShowLastTime(flag bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.showLastTime = flag
 }
 
@@ -0,0 +1,1203 @@
+package ProgressBar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock 可手动推进的时钟
+type fakeClock struct {
+	t time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.t
+}
+
+func (f *fakeClock) advance(ms int64) {
+	f.t = f.t.Add(time.Duration(ms) * time.Millisecond)
+}
+
+// newTestBar 创建一个固定宽度、输出到缓冲区、使用假时钟的进度条
+func newTestBar(total int64, width int) (*Config, *fakeClock, *bytes.Buffer) {
+	clk := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	buf := &bytes.Buffer{}
+	c := ProgressBar(total).SetWidth(width).SetOutput(buf).SetMode(ModeInteractive)
+	c.now = clk.Now
+	c.startTime = c.now()
+	return c, clk, buf
+}
+
+func TestRenderGolden(t *testing.T) {
+	tests := []struct {
+		name  string
+		width int
+		setup func(c *Config)
+		want  string
+	}{
+		{"percent/40", 40, func(c *Config) { c.ShowProgress(false).ShowPercent(true) },
+			"[=============>                 ] 42.0%"},
+		{"percent/60", 60, func(c *Config) { c.ShowProgress(false).ShowPercent(true) },
+			"[=====================>                             ] 42.0%"},
+		{"counts/40", 40, func(c *Config) {},
+			"[============>                ]  42/100"},
+		{"counts/60", 60, func(c *Config) {},
+			"[====================>                            ]  42/100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _, _ := newTestBar(100, tt.width)
+			tt.setup(c)
+			c.current = 42
+			got := c.Render()
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+			if len(got) != tt.width-1 {
+				t.Errorf("len = %d, want %d", len(got), tt.width-1)
+			}
+		})
+	}
+}
+
+func TestRenderBytes(t *testing.T) {
+	c, _, _ := newTestBar(1<<20, 60)
+	c.SetUnit(UnitBytes)
+	c.current = 512 * 1024
+	want := "[==================>                  ]  512.0 KB/   1.0 MB"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderSpeed(t *testing.T) {
+	c, clk, _ := newTestBar(100, 60)
+	c.ShowSpeed(true)
+	c.current = 10
+	c.Render()
+	clk.advance(2000)
+	c.current = 30
+	want := "[=========>                     ]  30/100 (  10.00 items/s)"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderETA(t *testing.T) {
+	c, clk, _ := newTestBar(100, 60)
+	c.ShowUsedTime(true)
+	c.ShowLastTime(true)
+	clk.advance(30000)
+	c.current = 25
+	want := "[=======>                     ]  25/100 [00:00:30/00:01:30]"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestShowProgressBarOutput(t *testing.T) {
+	c, _, buf := newTestBar(10, 20)
+	c.Update(5)
+	want := "\r[=====>     ]  5/10"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	c.Update(10)
+	want = "\r[===========] 10/10\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestLeftJustifyStableWidth(t *testing.T) {
+	c, clk, _ := newTestBar(1000, 80)
+	c.ShowSpeed(true).SetLeftJustify(true)
+	c.ShowUsedTime(true)
+	c.ShowLastTime(true)
+
+	first := c.Render()
+	want := "[>                            ]    0/1000                   [00:00:00/--:--:--]"
+	if first != want || len(first) != 79 {
+		t.Errorf("Render() = %q, want %q", first, want)
+	}
+	barEnd := strings.Index(first, "]")
+	for _, step := range []int64{5, 300, 20} {
+		clk.advance(1000)
+		c.current += step
+		line := c.Render()
+		if got := strings.Index(line, "]"); got != barEnd {
+			t.Errorf("bar end moved from %d to %d: %q", barEnd, got, line)
+		}
+	}
+}
+
+func TestSummaryWriter(t *testing.T) {
+	c, clk, buf := newTestBar(100, 40)
+	var log bytes.Buffer
+	c.SetSummaryWriter(&log)
+	clk.advance(4000)
+	c.Update(40)
+	c.Finish()
+	c.Finish()
+
+	if got, want := log.String(), "40/100 in 00:00:04, avg 10.00 items/s\n"; got != want {
+		t.Errorf("summary = %q, want %q", got, want)
+	}
+	if !strings.HasSuffix(buf.String(), "\n") {
+		t.Errorf("output %q should end with newline", buf.String())
+	}
+
+	c, clk, _ = newTestBar(2048, 40)
+	log.Reset()
+	c.SetUnit(UnitBytes).SetSummaryWriter(&log)
+	clk.advance(2000)
+	c.Update(2048)
+	c.Finish()
+	if got, want := log.String(), "2.0 KB/2.0 KB in 00:00:02, avg 1.0 KB/s\n"; got != want {
+		t.Errorf("summary = %q, want %q", got, want)
+	}
+}
+
+func TestRefreshFPS(t *testing.T) {
+	c, clk, buf := newTestBar(100, 20)
+	c.SetRefreshFPS(10)
+	if c.refreshInterval != 100*time.Millisecond {
+		t.Fatalf("refreshInterval = %v, want 100ms", c.refreshInterval)
+	}
+
+	c.Update(1)
+	clk.advance(50)
+	c.Update(2) // 被限流
+	clk.advance(50)
+	c.Update(3)
+	c.Update(100) // 完成帧不受限流
+	if got := strings.Count(buf.String(), "\r"); got != 3 {
+		t.Errorf("renders = %d, want 3: %q", got, buf.String())
+	}
+
+	if c.SetRefreshFPS(0).refreshInterval != 0 || c.SetRefreshFPS(-5).refreshInterval != 0 {
+		t.Error("non-positive fps should disable throttling")
+	}
+}
+
+func TestRenderOverflow(t *testing.T) {
+	c, _, buf := newTestBar(100, 40)
+	c.ShowPercent(true)
+	c.Update(110)
+	want := "\r[====================] 100.0% (110/100)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	c.ShowOverflow(true)
+	want = "[==============] 100.0% (110/100) (+10)"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestSpeedAvgOnComplete(t *testing.T) {
+	c, clk, buf := newTestBar(100, 60)
+	c.ShowSpeed(true)
+	clk.advance(1000)
+	c.Update(10)
+	clk.advance(1000)
+	c.Update(90)
+	buf.Reset()
+	clk.advance(2000)
+	c.Update(100) // 瞬时 5/s，全程平均 25/s
+	if !strings.Contains(buf.String(), "(  25.00 items/s)") {
+		t.Errorf("completion line should show average speed: %q", buf.String())
+	}
+
+	c, clk, buf = newTestBar(100, 60)
+	c.ShowSpeed(true).ShowSpeedAvgOnComplete(false)
+	clk.advance(1000)
+	c.Update(10)
+	clk.advance(1000)
+	c.Update(90)
+	buf.Reset()
+	clk.advance(2000)
+	c.Update(100)
+	if !strings.Contains(buf.String(), "(   5.00 items/s)") {
+		t.Errorf("completion line should show last sample: %q", buf.String())
+	}
+}
+
+func TestStateQueries(t *testing.T) {
+	c, clk, _ := newTestBar(10, 40)
+	if c.IsStarted() || c.IsComplete() {
+		t.Fatal("new bar should be neither started nor complete")
+	}
+	clk.advance(1500)
+	c.Update(4)
+	if !c.IsStarted() || c.IsComplete() {
+		t.Error("bar at 4/10 should be started but not complete")
+	}
+	if got := c.Elapsed(); got != 1500*time.Millisecond {
+		t.Errorf("Elapsed() = %v, want 1.5s", got)
+	}
+	c.Update(10)
+	if !c.IsComplete() {
+		t.Error("bar at 10/10 should be complete")
+	}
+}
+
+func TestResizeClearsPreviousLine(t *testing.T) {
+	c, _, buf := newTestBar(10, 40)
+	c.Update(5)
+	buf.Reset()
+	c.resize(20)
+	want := "\x1b[1A\r\x1b[J\r[=====>     ]  5/10"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	c.resize(30)
+	want = "\r\x1b[J\r[==========>          ]  5/10"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	c.SetNewlineOnResize(true)
+	c.resize(20)
+	want = "\n\r[=====>     ]  5/10"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestRounding(t *testing.T) {
+	tests := []struct {
+		mode Rounding
+		want string
+	}{
+		{RoundFloor, "[========>] 99.0%"},
+		{RoundNearest, "[=========] 99.0%"},
+		{RoundCeil, "[=========] 99.0%"},
+	}
+	for _, tt := range tests {
+		c, _, _ := newTestBar(100, 18)
+		c.ShowProgress(false).ShowPercent(true).SetRounding(tt.mode)
+		c.current = 99
+		if got := c.Render(); got != tt.want {
+			t.Errorf("mode %d: Render() = %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+
+	c, _, _ := newTestBar(100, 18)
+	c.ShowProgress(false).ShowPercent(true).SetRounding(RoundCeil)
+	c.current = 1
+	if got, want := c.Render(), "[=>        ] 1.0%"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestAddAndUpdateSemantics(t *testing.T) {
+	c, _, buf := newTestBar(10, 20)
+	c.Add(3)
+	c.Increment()
+	if c.current != 4 {
+		t.Fatalf("current = %d, want 4", c.current)
+	}
+
+	// Update 是绝对值，相同值也会输出，也允许回退
+	buf.Reset()
+	c.Update(4)
+	if buf.Len() == 0 {
+		t.Error("Update with an equal value should still render")
+	}
+	c.Update(2)
+	if c.current != 2 {
+		t.Errorf("current = %d, want 2", c.current)
+	}
+	c.Add(-5)
+	if c.current != 0 {
+		t.Errorf("current = %d, want 0", c.current)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	c, clk, _ := newTestBar(100, 60)
+	c.ShowSpeed(true)
+	c.ShowUsedTime(true)
+	c.Render()
+	clk.advance(1000)
+	c.current = 5
+	want := "[>           ]   5/100 (   5.00 items/s) [Elapsed:00:00:01]"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+
+	c.SetLabels(ChineseLabels)
+	clk.advance(1000)
+	c.current = 10
+	if got := c.Render(); !strings.Contains(got, "(   5.00 项/s) [已用:00:00:02]") {
+		t.Errorf("Render() = %q, want Chinese labels", got)
+	}
+}
+
+func TestHideBar(t *testing.T) {
+	c, clk, _ := newTestBar(1000, 80)
+	c.SetShowBar(false).ShowPercent(true)
+	c.ShowLastTime(true)
+	clk.advance(10000)
+	c.current = 500
+	want := "50.0% ( 500/1000) [ETA:00:00:10]"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestIterationCount(t *testing.T) {
+	c, clk, buf := newTestBar(0, 80)
+	c.SetShowIterationCount(true).ShowSpeed(true)
+	c.ShowUsedTime(true)
+	c.Update(0)
+	clk.advance(2000)
+	c.Update(112)
+	want := "\r0 items processed 00:00:00\r112 items processed (56.00 items/s) 00:00:02"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	if c.IsComplete() {
+		t.Error("a bar without total should not complete on its own")
+	}
+	c.Finish()
+	if !strings.HasSuffix(buf.String(), "\n") {
+		t.Errorf("Finish should end the line: %q", buf.String())
+	}
+}
+
+func TestPercentPosition(t *testing.T) {
+	c, _, _ := newTestBar(100, 30)
+	c.ShowProgress(false).ShowPercent(true).SetPercentPosition(PercentLeft)
+	c.current = 42
+	want := "42.0% [========>            ]"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+
+	c.SetPercentPosition(PercentRight)
+	want = "[========>            ] 42.0%"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+
+	c.SetPercentPosition(PercentLeft).SetShowBar(false).ShowProgress(true)
+	if got, want := c.Render(), "42.0% ( 42/100)"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	c, _, _ = newTestBar(100, 50)
+	c.ShowPercent(true)
+	c.ShowLastTime(true)
+	c.SetPercentPosition(PercentEnd).SetSuffix("done")
+	c.current = 42
+	want = "[====>      ] ( 42/100) [ETA:00:00:00] 42.0% done"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestDoneChannel(t *testing.T) {
+	isClosed := func(ch <-chan struct{}) bool {
+		select {
+		case <-ch:
+			return true
+		default:
+			return false
+		}
+	}
+
+	c, _, _ := newTestBar(10, 20)
+	c.Update(5)
+	if isClosed(c.Done()) {
+		t.Fatal("Done closed before completion")
+	}
+	c.Update(10)
+	c.Update(10)
+	c.Finish()
+	if !isClosed(c.Done()) {
+		t.Error("Done should close on reaching total")
+	}
+
+	c, _, _ = newTestBar(10, 20)
+	c.Fail(io.ErrUnexpectedEOF)
+	c.Fail(io.EOF)
+	if !isClosed(c.Done()) || c.err != io.ErrUnexpectedEOF {
+		t.Errorf("Fail should close Done once and keep the first error, err = %v", c.err)
+	}
+
+	c, _, _ = newTestBar(1000, 20)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Increment()
+			}
+		}()
+	}
+	wg.Wait()
+	<-c.Done()
+}
+
+func TestEmptyChar(t *testing.T) {
+	c, _, _ := newTestBar(10, 20)
+	c.SetEmptyChar("░")
+	want := "[>░░░░░░░░░░]  0/10"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	c.current = 5
+	want = "[=====>░░░░░]  5/10"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderOnUpdateDisabled(t *testing.T) {
+	c, _, buf := newTestBar(10, 20)
+	c.SetRenderOnUpdate(false)
+	c.Add(3)
+	c.Increment()
+	c.Update(10)
+	if buf.Len() != 0 {
+		t.Fatalf("updates should not render: %q", buf.String())
+	}
+	select {
+	case <-c.Done():
+	default:
+		t.Error("Done should still close without rendering")
+	}
+	c.ShowProgressBar()
+	if got, want := buf.String(), "\r[===========] 10/10\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestDrawBypassesThrottle(t *testing.T) {
+	c, _, buf := newTestBar(10, 20)
+	c.SetRefreshInterval(time.Hour)
+	c.Update(1)
+	c.Update(2) // 被限流
+	c.Draw()
+	if got, want := buf.String(), "\r[=>         ]  1/10\r[==>        ]  2/10"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	c.Finish()
+	buf.Reset()
+	c.Draw()
+	if buf.Len() != 0 {
+		t.Errorf("Draw after Finish should not render: %q", buf.String())
+	}
+}
+
+func TestPercentSnapsAtTotal(t *testing.T) {
+	c, _, _ := newTestBar(3, 30)
+	c.ShowPercent(true)
+	c.current = 2
+	if got, want := c.Render(), "[=========>     ] 66.7% (2/3)"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	c.current = 3
+	if got, want := c.Render(), "[==============] 100.0% (3/3)"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	if p := c.Snapshot().Percent; p != 100 {
+		t.Errorf("Percent = %v, want exactly 100", p)
+	}
+
+	const big = int64(1)<<62 + 7
+	c, _, _ = newTestBar(big, 30)
+	c.current = big
+	if p := c.Snapshot().Percent; p != 100 {
+		t.Errorf("Percent = %v, want exactly 100", p)
+	}
+}
+
+func TestRenderBytesMatchesRender(t *testing.T) {
+	c, _, _ := newTestBar(10, 20)
+	c.current = 5
+	first := c.RenderBytes()
+	if got, want := string(first), c.Render(); got != want {
+		t.Errorf("RenderBytes() = %q, want %q", got, want)
+	}
+	c.current = 6
+	second := c.RenderBytes()
+	if &first[0] != &second[0] {
+		t.Error("RenderBytes should reuse its buffer")
+	}
+}
+
+func TestNewlineOnlyAtTotal(t *testing.T) {
+	c, _, buf := newTestBar(10, 20)
+	c.Update(9)
+	if strings.Contains(buf.String(), "\n") {
+		t.Fatalf("newline before total: %q", buf.String())
+	}
+	c.Update(10)
+	if !strings.HasSuffix(buf.String(), "\n") {
+		t.Errorf("no newline at total: %q", buf.String())
+	}
+}
+
+func TestSetCompleteAt(t *testing.T) {
+	c, _, buf := newTestBar(10, 20)
+	c.SetCompleteAt(8)
+	c.Update(7)
+	if c.IsComplete() {
+		t.Fatal("complete before threshold")
+	}
+	c.Update(8)
+	if !c.IsComplete() || !strings.HasSuffix(buf.String(), "\n") {
+		t.Errorf("not complete at threshold: %q", buf.String())
+	}
+	if s := c.Snapshot(); s.Percent != 80 {
+		t.Errorf("Percent = %v, want 80", s.Percent)
+	}
+}
+
+func TestCompletionLineOnce(t *testing.T) {
+	c, _, buf := newTestBar(10, 20)
+	c.SetCompleteAt(5)
+	c.Update(5)
+	c.Update(6)
+	c.Add(2)
+	c.Update(10)
+	c.Draw()
+	c.Finish()
+	if n := strings.Count(buf.String(), "\n"); n != 1 {
+		t.Errorf("completion newline written %d times: %q", n, buf.String())
+	}
+	if got := c.Snapshot().Current; got != 10 {
+		t.Errorf("Current = %d, want updates past the threshold to be counted", got)
+	}
+
+	// 超出总数时同样只输出一次
+	c, _, buf = newTestBar(10, 20)
+	c.Update(10)
+	c.Update(12)
+	c.Add(1)
+	if n := strings.Count(buf.String(), "\n"); n != 1 {
+		t.Errorf("past total: completion newline written %d times: %q", n, buf.String())
+	}
+}
+
+func TestRenderTriggersShareThrottle(t *testing.T) {
+	c, clk, buf := newTestBar(10, 40)
+	c.SetRefreshInterval(time.Second)
+	c.Update(5)
+	buf.Reset()
+	c.resize(20)
+	c.resize(30)
+	c.Tick()
+	c.Update(6)
+	if buf.Len() != 0 {
+		t.Fatalf("throttled triggers wrote %q", buf.String())
+	}
+	clk.advance(1000)
+	c.Tick()
+	want := "\x1b[1A\r\x1b[J\r[============>        ]  6/10"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestElapsedAndETA(t *testing.T) {
+	c, clk, _ := newTestBar(100, 40)
+	if eta, ok := c.ETA(); ok || eta != -1 {
+		t.Errorf("ETA() = %v, %v, want -1, false", eta, ok)
+	}
+	clk.advance(2000)
+	c.current = 25
+	if got := c.Elapsed(); got != 2*time.Second {
+		t.Errorf("Elapsed() = %v, want 2s", got)
+	}
+	if eta, ok := c.ETA(); !ok || eta != 6*time.Second {
+		t.Errorf("ETA() = %v, %v, want 6s, true", eta, ok)
+	}
+}
+
+func TestStderr(t *testing.T) {
+	c := Stderr(10)
+	defer c.SetAutoWidth(false)
+	if c.out != os.Stderr {
+		t.Errorf("out = %v, want os.Stderr", c.out)
+	}
+	if c.width < minWidth {
+		t.Errorf("width = %d, want at least %d", c.width, minWidth)
+	}
+}
+
+func TestCloseIsDeferSafe(t *testing.T) {
+	c := ProgressBar(10).SetOutput(io.Discard)
+	c.SetAutoRender(time.Hour)
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("second Close() = %v", err)
+	}
+	if c.resizeStop != nil || c.autoStop != nil {
+		t.Error("Close should stop background goroutines")
+	}
+	select {
+	case <-c.Done():
+	default:
+		t.Error("Done should be closed after Close")
+	}
+
+	// 已完成后再关闭不应再输出
+	c, _, buf := newTestBar(2, 20)
+	c.Update(2)
+	n := buf.Len()
+	c.Close()
+	if buf.Len() != n {
+		t.Errorf("Close after completion wrote %q", buf.String()[n:])
+	}
+}
+
+func TestTotalUnknownText(t *testing.T) {
+	c, _, _ := newTestBar(0, 20)
+	c.current = 420
+	want := "[>          ] 420/?"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	c.SetTotalUnknownText("unknown")
+	want = "[>    ] 420/unknown"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestShorterLineClearsLeftover(t *testing.T) {
+	c, _, buf := newTestBar(10, 20)
+	c.ShowProgress(false).SetShowBar(false).SetSuffix("long-name.txt")
+	c.Update(1)
+	buf.Reset()
+	c.SetSuffix("a")
+	c.Update(2)
+	if got, want := buf.String(), "\ra\x1b[K"; got != want {
+		t.Errorf("ANSI clear: output = %q, want %q", got, want)
+	}
+
+	c.SetPadToWidth(true)
+	buf.Reset()
+	c.SetSuffix("long-name.txt")
+	c.Update(3)
+	buf.Reset()
+	c.SetSuffix("a")
+	c.Update(4)
+	if got, want := buf.String(), "\ra"+strings.Repeat(" ", 18); got != want {
+		t.Errorf("pad: output = %q, want %q", got, want)
+	}
+}
+
+func TestFormatBytesLargeScales(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		unit  int64
+		want  string
+	}{
+		{3 << 50, 1024, "   3.0 PB"},
+		{5 << 60, 1024, "   5.0 EB"},
+		{math.MaxInt64, 1024, "   8.0 EB"},
+		{2_000_000_000_000_000, 1000, "   2.0 PB"},
+		{math.MaxInt64, 1000, "   9.2 EB"},
+		{math.MaxInt64, 2, "144115188075855872.0 EB"},
+	}
+	for _, tt := range tests {
+		if got := formatSize(tt.bytes, tt.unit, false); got != tt.want {
+			t.Errorf("formatSize(%d, %d) = %q, want %q", tt.bytes, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestWriteKeepsDataOutOfRender(t *testing.T) {
+	c, _, buf := newTestBar(12, 30)
+	n, err := io.Copy(c, strings.NewReader("secret-data!"))
+	if err != nil || n != 12 {
+		t.Fatalf("io.Copy = %d, %v", n, err)
+	}
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("data leaked into render output: %q", buf.String())
+	}
+	if want := "\r[=====================] 12/12\n"; buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestBytesPerUnit(t *testing.T) {
+	c, _, _ := newTestBar(1000, 50)
+	c.SetBytesPerUnit(10 * 1024)
+	c.current = 420
+	want := "[========>           ]  420/1000 (~4.1 MB/9.8 MB)"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestWait(t *testing.T) {
+	c, _, _ := newTestBar(3, 20)
+	go func() {
+		for i := 0; i < 3; i++ {
+			c.Increment()
+		}
+	}()
+	if err := c.Wait(); err != nil {
+		t.Errorf("Wait() = %v, want nil", err)
+	}
+
+	c, _, _ = newTestBar(3, 20)
+	boom := errors.New("boom")
+	go c.Fail(boom)
+	if err := c.Wait(); err != boom {
+		t.Errorf("Wait() = %v, want %v", err, boom)
+	}
+
+	// 写入失败时返回第一次写入的错误
+	closed := errors.New("closed pipe")
+	c, _, _ = newTestBar(3, 20)
+	c.SetOutput(&failingWriter{after: 1, err: closed})
+	c.Update(1)
+	c.Update(3)
+	if err := c.Wait(); err != closed {
+		t.Errorf("Wait() = %v, want %v", err, closed)
+	}
+}
+
+// 前 after 次写入成功，之后每次都返回 err
+type failingWriter struct {
+	after int
+	err   error
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.after > 0 {
+		w.after--
+		return len(p), nil
+	}
+	return 0, w.err
+}
+
+// 用 go test -race 运行时检查并发调用没有数据竞争，且最终状态一致
+func TestConcurrentStress(t *testing.T) {
+	c, _, _ := newTestBar(0, 60)
+	c.ShowPercent(true).ShowSpeed(true)
+	const workers, loops = 16, 200
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < loops; j++ {
+				switch j % 3 {
+				case 0:
+					c.SetTotal(int64(workers * loops * 2))
+				case 1:
+					c.Draw()
+				case 2:
+					c.Snapshot()
+				}
+				c.Increment()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if got := c.Snapshot().Current; got != workers*loops {
+		t.Errorf("Current = %d, want %d", got, workers*loops)
+	}
+	c.Update(workers * loops * 2)
+	if !c.IsComplete() {
+		t.Error("bar should complete at total")
+	}
+	if got, want := c.Render(), "] 100.0% (6400/6400)"; !strings.HasSuffix(got, want) {
+		t.Errorf("Render() = %q, want suffix %q", got, want)
+	}
+}
+
+// Update、Add、Finish、Close 与其他调用并发，结束后状态一致且只输出一次结束换行
+func TestConcurrentStressWithUpdateAndFinish(t *testing.T) {
+	c, _, buf := newTestBar(1<<20, 60)
+	c.ShowPercent(true).ShowSpeed(true)
+	const workers, loops = 16, 200
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < loops; j++ {
+				switch j % 5 {
+				case 0:
+					c.Update(next.Add(10))
+				case 1:
+					c.Add(3)
+				case 2:
+					c.Draw()
+				case 3:
+					c.SetTotal(1 << 20)
+				}
+				c.Increment()
+				if i == 0 && j == loops/2 {
+					c.Finish()
+				}
+				if i == 1 && j == loops-1 {
+					c.Close()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	final := c.Snapshot()
+	if !final.Done {
+		t.Error("bar should be finished")
+	}
+	select {
+	case <-c.Done():
+	default:
+		t.Error("Done should be closed")
+	}
+	c.Update(final.Current + 100)
+	c.Increment()
+	if got := c.Snapshot().Current; got != final.Current {
+		t.Errorf("Current changed after Finish: %d -> %d", final.Current, got)
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 1 {
+		t.Errorf("finishing newline written %d times", n)
+	}
+}
+
+func TestTimeFormatters(t *testing.T) {
+	c, clk, _ := newTestBar(100, 50)
+	c.ShowProgress(false)
+	c.ShowUsedTime(true)
+	c.ShowLastTime(true)
+	c.SetElapsedFormat(time.Duration.String).SetETAFormat(func(d time.Duration) string {
+		return d.Round(time.Minute).String()
+	})
+	clk.advance(200 * 1000)
+	c.current = 50
+	want := "[=================>                ] [3m20s/3m0s]"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestSameUnit(t *testing.T) {
+	c, _, _ := newTestBar(100<<20, 50)
+	c.SetUnit(UnitBytes).SetSameUnit(true)
+	c.current = 10 << 10
+	want := "[>                             ]   0.01/100.00 MB"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	c.current = 50 << 20
+	want = "[===============>              ]  50.00/100.00 MB"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestThousandsSeparator(t *testing.T) {
+	c, _, _ := newTestBar(9999999, 40)
+	c.SetThousandsSeparator(',')
+	c.current = 1234567
+	want := "[==>              ] 1,234,567/9,999,999"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	c.current = 12
+	want = "[>                ]        12/9,999,999"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	c.SetThousandsSeparator(' ')
+	if got := c.countStr(-1234); got != "-1 234" {
+		t.Errorf("countStr(-1234) = %q", got)
+	}
+}
+
+func TestCompletionMessage(t *testing.T) {
+	c, _, buf := newTestBar(10, 20)
+	c.SetCompletionMessage("done!", CompletionKeep)
+	c.Update(10)
+	if got, want := buf.String(), "\r[===========] 10/10\ndone!\n"; got != want {
+		t.Errorf("keep: output = %q, want %q", got, want)
+	}
+
+	c, _, buf = newTestBar(10, 20)
+	c.SetCompletionMessage("done!", CompletionReplace)
+	c.Update(5)
+	buf.Reset()
+	c.Update(10)
+	if got, want := buf.String(), "\rdone!\x1b[K\n"; got != want {
+		t.Errorf("replace: output = %q, want %q", got, want)
+	}
+
+	c, _, buf = newTestBar(10, 20)
+	c.SetCompletionMessage("done!", CompletionKeep)
+	c.Fail(io.ErrUnexpectedEOF)
+	if strings.Contains(buf.String(), "done!") {
+		t.Errorf("Fail should not print the completion message: %q", buf.String())
+	}
+}
+
+func TestBarWidthRatio(t *testing.T) {
+	c, _, _ := newTestBar(10, 40)
+	c.SetBarWidthRatio(0.5)
+	c.current = 5
+	want := "[=====>     ]  5/10"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	c.resize(60)
+	want = "[==========>          ]  5/10"
+	if got := c.Render(); got != want {
+		t.Errorf("after resize Render() = %q, want %q", got, want)
+	}
+}
+
+func TestUpdateGranularity(t *testing.T) {
+	c, _, buf := newTestBar(100, 20)
+	c.SetUpdateGranularity(10)
+	for i := 0; i < 9; i++ {
+		c.Increment()
+	}
+	if buf.Len() != 0 || c.Snapshot().Current != 0 {
+		t.Fatalf("increments below granularity should be buffered, output %q", buf.String())
+	}
+	c.Increment()
+	if got := c.Snapshot().Current; got != 10 {
+		t.Errorf("Current = %d, want 10", got)
+	}
+	c.Add(5)
+	c.Finish()
+	if got := c.Snapshot().Current; got != 15 {
+		t.Errorf("Current after Finish = %d, want 15", got)
+	}
+}
+
+func TestUpdateGranularityCompletes(t *testing.T) {
+	c, _, buf := newTestBar(100, 20)
+	c.SetUpdateGranularity(30)
+	for i := 0; i < 100; i++ {
+		c.Increment()
+	}
+	if got := c.Snapshot().Current; got != 100 {
+		t.Errorf("Current = %d, want 100", got)
+	}
+	if !c.IsComplete() {
+		t.Error("bar should complete without Finish")
+	}
+	select {
+	case <-c.Done():
+	default:
+		t.Error("Done should be closed once the total is reached")
+	}
+	if !strings.HasSuffix(buf.String(), "100/100\n") {
+		t.Errorf("completion line not drawn: %q", buf.String())
+	}
+
+	// SetCompleteAt 的阈值同样触发提交
+	c, _, _ = newTestBar(100, 20)
+	c.SetUpdateGranularity(30).SetCompleteAt(10)
+	c.Add(10)
+	if !c.IsComplete() {
+		t.Errorf("Current = %d, want completion at 10", c.Snapshot().Current)
+	}
+}
+
+func TestUpdateDropsBufferedIncrements(t *testing.T) {
+	c, _, _ := newTestBar(100, 20)
+	c.SetUpdateGranularity(30)
+	c.Add(5)
+	c.Update(100)
+	c.Finish()
+	if got := c.Snapshot().Current; got != 100 {
+		t.Errorf("Current = %d, want 100", got)
+	}
+
+	c, _, _ = newTestBar(100, 20)
+	c.SetUpdateGranularity(30)
+	c.Add(5)
+	c.UpdateWithLabel(50, "x")
+	c.Finish()
+	if got := c.Snapshot().Current; got != 50 {
+		t.Errorf("Current after UpdateWithLabel = %d, want 50", got)
+	}
+}
+
+func BenchmarkIncrement(b *testing.B) {
+	for _, g := range []int64{1, 4096} {
+		b.Run(fmt.Sprintf("granularity=%d", g), func(b *testing.B) {
+			c := ProgressBar(int64(b.N) + 1).SetWidth(80).SetOutput(io.Discard).SetRenderOnUpdate(false)
+			c.SetUpdateGranularity(g)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					c.Increment()
+				}
+			})
+		})
+	}
+}
+
+func TestSetStartTime(t *testing.T) {
+	c, clk, _ := newTestBar(100, 40)
+	c.SetStartTime(clk.Now().Add(-time.Hour))
+	c.SetRenderOnUpdate(false).Update(50)
+	if got := c.Elapsed(); got != time.Hour {
+		t.Errorf("Elapsed() = %v, want 1h", got)
+	}
+	if eta, _ := c.ETA(); eta != time.Hour {
+		t.Errorf("ETA() = %v, want 1h", eta)
+	}
+}
+
+func TestSealedAfterFinish(t *testing.T) {
+	c, _, buf := newTestBar(10, 20)
+	c.Update(3)
+	c.Finish()
+	n := buf.Len()
+	c.Update(5)
+	c.Add(1)
+	c.Increment()
+	c.SetTotal(20)
+	c.ShowProgressBar()
+	c.Draw()
+	if buf.Len() != n {
+		t.Errorf("updates after Finish wrote %q", buf.String()[n:])
+	}
+	if s := c.Snapshot(); s.Current != 3 || s.Total != 10 {
+		t.Errorf("state changed after Finish: %+v", s)
+	}
+}
+
+func TestFormatShortTime(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{1500 * time.Millisecond, "1s"},
+		{65 * time.Second, "1m5s"},
+		{time.Hour + 2*time.Minute + 5*time.Second, "1h2m5s"},
+		{26 * time.Hour, "26h0m0s"},
+	}
+	for _, tt := range tests {
+		if got := formatShortTime(tt.d); got != tt.want {
+			t.Errorf("formatShortTime(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestAbbreviatedTime(t *testing.T) {
+	c, clk, _ := newTestBar(100, 40)
+	c.ShowProgress(false)
+	c.ShowUsedTime(true)
+	c.ShowLastTime(true)
+	c.SetAbbreviatedTime(true)
+	clk.advance(65 * 1000)
+	c.current = 50
+	want := "[============>            ] [1m5s/1m5s]"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestTotalStrConsistentAcrossCallOrder(t *testing.T) {
+	a, _, _ := newTestBar(0, 40)
+	a.SetUnit(UnitBytes)
+	a.SetTotal(2 << 20)
+
+	b, _, _ := newTestBar(0, 40)
+	b.SetTotal(2 << 20)
+	b.SetUnit(UnitBytes)
+
+	for _, c := range []*Config{a, b} {
+		if c.totalStr != "   2.0 MB" {
+			t.Errorf("totalStr = %q, want %q", c.totalStr, "   2.0 MB")
+		}
+	}
+	a.SetUnit(UnitRaw)
+	if a.totalStr != "2097152" {
+		t.Errorf("totalStr after SetUnit(UnitRaw) = %q", a.totalStr)
+	}
+}
+
+func TestIndependentBrackets(t *testing.T) {
+	tests := []struct {
+		percent  bool
+		counts   *bool
+		pctParen bool
+		want     string
+	}{
+		{false, nil, false, "[=====>     ]  5/10"},
+		{true, nil, false, "[=> ] 50.0% ( 5/10)"},
+		{true, &[]bool{false}[0], false, "[==>  ] 50.0%  5/10"},
+		{false, &[]bool{true}[0], false, "[====>    ] ( 5/10)"},
+		{true, &[]bool{true}[0], true, "[>] (50.0%) ( 5/10)"},
+	}
+	for i, tt := range tests {
+		c, _, _ := newTestBar(10, 20)
+		c.ShowPercent(tt.percent).SetPercentBrackets(tt.pctParen)
+		if tt.counts != nil {
+			c.SetCountsBrackets(*tt.counts)
+		}
+		c.current = 5
+		if got := c.Render(); got != tt.want {
+			t.Errorf("case %d: Render() = %q, want %q", i, got, tt.want)
+		}
+	}
+}
+
+func TestSetOutputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.log")
+	c, _, _ := newTestBar(10, 20)
+	if err := c.SetOutputFile(path); err != nil {
+		t.Fatalf("SetOutputFile() = %v", err)
+	}
+	if !c.plain() {
+		t.Error("SetOutputFile should switch to plain mode")
+	}
+	c.Update(5)
+	c.Update(10)
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[=====>     ]  5/10\n[===========] 10/10\n"
+	if string(data) != want {
+		t.Errorf("file content = %q, want %q", data, want)
+	}
+	if c.outFile != nil {
+		t.Error("Close should release the file")
+	}
+
+	if err := c.SetOutputFile(filepath.Join(t.TempDir(), "missing", "x.log")); err == nil {
+		t.Error("SetOutputFile should report open errors")
+	}
+}
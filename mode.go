@@ -0,0 +1,78 @@
+package ProgressBar
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Mode 输出方式
+type Mode int
+
+const (
+	ModeAuto        Mode = iota // 0: 输出目标是终端时原地刷新，否则逐行输出
+	ModeInteractive             // 1: 总是用 \r 原地刷新
+	ModePlain                   // 2: 总是逐行输出，适合日志和 CI
+)
+
+// 逐行输出且未设置刷新间隔时的默认间隔
+const defaultPlainInterval = time.Second
+
+// SetMode 设置输出方式，默认 ModeAuto
+func (c *Config) SetMode(mode Mode) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mode = mode
+	c.applyFallback()
+	return c
+}
+
+// SetOutputFallback 按顺序选择第一个是终端的输出目标，不传参数时为 os.Stdout、os.Stderr；
+// 都不是终端时：ModeAuto 下输出到 io.Discard，避免在无终端的环境中输出控制字符或刷屏，
+// 明确设置了 ModePlain 或 ModeInteractive 时输出到第一个目标
+func (c *Config) SetOutputFallback(ws ...io.Writer) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(ws) == 0 {
+		ws = []io.Writer{os.Stdout, os.Stderr}
+	}
+	c.fallback = ws
+	c.applyFallback()
+	return c
+}
+
+// 按备选输出目标和输出方式选择实际的输出目标，调用方需持有锁
+func (c *Config) applyFallback() {
+	if len(c.fallback) == 0 {
+		return
+	}
+	for _, w := range c.fallback {
+		if isTerminal(w) {
+			c.out, c.outIsTTY = w, true
+			return
+		}
+	}
+	c.out, c.outIsTTY = c.fallback[0], false
+	if c.mode == ModeAuto {
+		c.out = io.Discard
+	}
+}
+
+// 当前是否为逐行输出
+func (c *Config) plain() bool {
+	switch c.mode {
+	case ModeInteractive:
+		return false
+	case ModePlain:
+		return true
+	}
+	return !c.outIsTTY
+}
+
+// 判断输出目标是否为终端
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
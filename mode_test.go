@@ -0,0 +1,87 @@
+package ProgressBar
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestAutoModeOnBuffer(t *testing.T) {
+	c, clk, buf := newTestBar(10, 20)
+	c.SetMode(ModeAuto)
+	if !c.plain() {
+		t.Fatal("buffer output should resolve to plain mode")
+	}
+
+	c.Update(1)
+	c.Update(2) // 默认按 1 秒限流
+	clk.advance(1000)
+	c.Update(3)
+	c.Update(10)
+	want := "[=>         ]  1/10\n[===>       ]  3/10\n[===========] 10/10\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	if strings.Contains(buf.String(), "\r") {
+		t.Error("plain mode should not emit carriage returns")
+	}
+}
+
+func TestExplicitModes(t *testing.T) {
+	c, _, _ := newTestBar(10, 20)
+	if c.plain() {
+		t.Error("ModeInteractive should not be plain")
+	}
+	if !c.SetMode(ModePlain).plain() {
+		t.Error("ModePlain should be plain")
+	}
+}
+
+func TestPlainModeWholeLines(t *testing.T) {
+	c, clk, buf := newTestBar(10, 30)
+	c.SetMode(ModePlain).SetSuffix("a\rb").SetCompletionMessage("done", CompletionKeep)
+	for i := 1; i <= 5; i++ {
+		clk.advance(1000)
+		c.Update(int64(i))
+	}
+	c.resize(25)
+	c.Draw()
+	c.Tick()
+	c.Finish()
+
+	out := buf.String()
+	if strings.Contains(out, "\r") {
+		t.Errorf("plain output contains \\r: %q", out)
+	}
+	if !strings.HasSuffix(out, "\n") {
+		t.Errorf("plain output should end with a newline: %q", out)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		if line == "" {
+			t.Errorf("unexpected empty line in %q", out)
+		}
+	}
+}
+
+func TestOutputFallback(t *testing.T) {
+	a, b := &bytes.Buffer{}, &bytes.Buffer{}
+	c, _, _ := newTestBar(10, 20)
+	c.SetMode(ModeAuto).SetOutputFallback(a, b)
+	c.Update(5)
+	if c.out != io.Discard || a.Len() != 0 || b.Len() != 0 {
+		t.Errorf("auto mode without a terminal should discard output, a=%q b=%q", a.String(), b.String())
+	}
+
+	c.SetMode(ModePlain)
+	c.Draw()
+	if got, want := a.String(), "[=====>     ]  5/10\n"; got != want {
+		t.Errorf("plain mode should use the first writer, got %q, want %q", got, want)
+	}
+
+	c.SetOutput(b)
+	c.SetMode(ModeAuto)
+	if c.out != b {
+		t.Error("SetOutput should cancel the fallback chain")
+	}
+}
@@ -0,0 +1,179 @@
+package ProgressBar
+
+import (
+	"cmp"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ProxyReader 包装 io.Reader，读取时自动推进进度条；
+// 进度条设置了刷新间隔时，读取的字节先在本地累计，每个间隔才提交一次，减少锁竞争；
+// 间隔在每次读取时从进度条读取，累计量达到完成阈值时立即提交
+type ProxyReader struct {
+	io.Reader
+	bar       *Config
+	pending   int64 // 尚未提交的字节数
+	lastFlush time.Time
+	label     string // 第一次读取时设置为进度条的行尾文字，空表示不设置
+	labeled   bool
+}
+
+func (r *ProxyReader) Read(p []byte) (int, error) {
+	if !r.labeled {
+		r.labeled = true
+		if r.label != "" {
+			r.bar.SetSuffix(r.label)
+		}
+	}
+	n, err := r.Reader.Read(p)
+	r.pending += int64(n)
+	interval := time.Duration(r.bar.flushInterval.Load())
+	if err != nil || interval == 0 || r.pending >= r.bar.untilDone.Load() ||
+		r.bar.now().Sub(r.lastFlush) >= interval {
+		r.flush()
+	}
+	return n, err
+}
+
+// Close 提交剩余的字节，底层 Reader 实现了 io.Closer 时一并关闭
+func (r *ProxyReader) Close() error {
+	r.flush()
+	if closer, ok := r.Reader.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// 将累计的字节提交到进度条
+func (r *ProxyReader) flush() {
+	r.lastFlush = r.bar.now()
+	if r.pending > 0 {
+		r.bar.Add(r.pending)
+		r.pending = 0
+	}
+}
+
+// NewProxyReader 返回一个读取时推进当前进度条的 Reader
+func (c *Config) NewProxyReader(r io.Reader) *ProxyReader {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &ProxyReader{Reader: r, bar: c, lastFlush: c.now()}
+}
+
+// NewLabeledProxyReader 同 NewProxyReader，第一次读取时把进度条的行尾文字设置为 label，
+// 适合多个文件依次通过同一个总进度条复制时显示当前文件名
+func (c *Config) NewLabeledProxyReader(r io.Reader, label string) *ProxyReader {
+	pr := c.NewProxyReader(r)
+	pr.label = label
+	return pr
+}
+
+// ProxyWriter 包装 io.Writer，写入成功的字节数推进进度条
+type ProxyWriter struct {
+	io.Writer
+	bar     *Config
+	label   string // 第一次写入时设置为进度条的行尾文字，空表示不设置
+	labeled bool
+}
+
+func (w *ProxyWriter) Write(p []byte) (int, error) {
+	if !w.labeled {
+		w.labeled = true
+		if w.label != "" {
+			w.bar.SetSuffix(w.label)
+		}
+	}
+	n, err := w.Writer.Write(p)
+	if n > 0 {
+		w.bar.Add(int64(n))
+	}
+	return n, err
+}
+
+// Close 底层 Writer 实现了 io.Closer 时将其关闭
+func (w *ProxyWriter) Close() error {
+	if closer, ok := w.Writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// NewProxyWriter 返回一个写入时推进当前进度条的 Writer
+func (c *Config) NewProxyWriter(w io.Writer) *ProxyWriter {
+	return &ProxyWriter{Writer: w, bar: c}
+}
+
+// NewLabeledProxyWriter 同 NewProxyWriter，第一次写入时把进度条的行尾文字设置为 label
+func (c *Config) NewLabeledProxyWriter(w io.Writer, label string) *ProxyWriter {
+	return &ProxyWriter{Writer: w, bar: c, label: label}
+}
+
+// ProxyWriterAt 包装 io.WriterAt，供多个连接按偏移量并发写入分段时使用；
+// 只把之前没写过的字节计入进度，重试或重叠的分段不会重复计数
+type ProxyWriterAt struct {
+	w   io.WriterAt
+	bar *Config
+
+	mu      sync.Mutex
+	written [][2]int64 // 已写入的区间 [start, end)，按起点排序且互不重叠
+}
+
+// WriteAt 写入底层 WriterAt，并把新覆盖的字节数计入进度条，可以并发调用
+func (w *ProxyWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	n, err := w.w.WriteAt(p, off)
+	if n > 0 {
+		if added := w.cover(off, off+int64(n)); added > 0 {
+			w.bar.Add(added)
+		}
+	}
+	return n, err
+}
+
+// 记录区间 [start, end)，返回其中之前未覆盖的字节数
+func (w *ProxyWriterAt) cover(start, end int64) int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	added := end - start
+	merged := make([][2]int64, 0, len(w.written)+1)
+	for _, r := range w.written {
+		if r[1] < start || r[0] > end {
+			merged = append(merged, r)
+			continue
+		}
+		// 与新区间重叠或相邻：扣除重叠部分后合并
+		if overlap := min(r[1], end) - max(r[0], start); overlap > 0 {
+			added -= overlap
+		}
+		start, end = min(r[0], start), max(r[1], end)
+	}
+	merged = append(merged, [2]int64{start, end})
+	slices.SortFunc(merged, func(a, b [2]int64) int { return cmp.Compare(a[0], b[0]) })
+	w.written = merged
+	return added
+}
+
+// NewProxyWriterAt 返回一个按偏移量写入时推进当前进度条的 WriterAt
+func (c *Config) NewProxyWriterAt(w io.WriterAt) *ProxyWriterAt {
+	return &ProxyWriterAt{w: w, bar: c}
+}
+
+// FromFile 根据文件大小创建字节单位的进度条，并返回包装后的 Reader
+func FromFile(f *os.File) (*Config, io.Reader, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	c := ProgressBar(info.Size()).SetUnit(UnitBytes)
+	return c, c.NewProxyReader(f), nil
+}
+
+// ProgressBarReader 为下载等场景一步创建进度条和包装后的 Reader：
+// 字节单位，显示速度和剩余时间，返回的进度条仍可继续设置
+func ProgressBarReader(r io.Reader, total int64) (*Config, *ProxyReader) {
+	c := ProgressBar(total).SetUnit(UnitBytes).ShowSpeed(true)
+	c.ShowLastTime(true)
+	return c, c.NewProxyReader(r)
+}
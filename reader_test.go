@@ -0,0 +1,215 @@
+package ProgressBar
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProxyReader(t *testing.T) {
+	c, _, buf := newTestBar(10, 40)
+	n, err := io.Copy(io.Discard, c.NewProxyReader(strings.NewReader("0123456789")))
+	if err != nil || n != 10 {
+		t.Fatalf("io.Copy = %d, %v", n, err)
+	}
+	if c.current != 10 {
+		t.Errorf("current = %d, want 10", c.current)
+	}
+	if !strings.HasSuffix(buf.String(), "\n") {
+		t.Errorf("output %q should end with newline", buf.String())
+	}
+}
+
+func TestFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	data := bytes.Repeat([]byte("x"), 4096)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	c, r, err := FromFile(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetOutput(io.Discard)
+	if c.total != 4096 || c.unit != UnitBytes {
+		t.Fatalf("total = %d, unit = %d", c.total, c.unit)
+	}
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		t.Fatal(err)
+	}
+	if c.current != 4096 {
+		t.Errorf("current = %d, want 4096", c.current)
+	}
+}
+
+func TestConfigAsWriter(t *testing.T) {
+	c, _, buf := newTestBar(10, 20)
+	var _ io.Writer = c
+	n, err := io.Copy(c, strings.NewReader("0123456789"))
+	if err != nil || n != 10 {
+		t.Fatalf("io.Copy = %d, %v", n, err)
+	}
+	if c.current != 10 {
+		t.Errorf("current = %d, want 10", c.current)
+	}
+	if strings.Contains(buf.String(), "0123") {
+		t.Errorf("written data leaked into output: %q", buf.String())
+	}
+	io.WriteString(c, "ab")
+	if c.current != 12 {
+		t.Errorf("current = %d, want 12", c.current)
+	}
+}
+
+// 每次只返回一个字节的 Reader
+type oneByteReader struct {
+	data []byte
+	clk  *fakeClock
+}
+
+func (r *oneByteReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	p[0] = r.data[0]
+	r.data = r.data[1:]
+	r.clk.advance(10)
+	return 1, nil
+}
+
+func TestProxyReaderBatches(t *testing.T) {
+	c, clk, _ := newTestBar(100, 40)
+	var renders int
+	c.SetRefreshInterval(100 * time.Millisecond).SetOnRender(func(Snapshot) { renders++ })
+	r := c.NewProxyReader(&oneByteReader{data: bytes.Repeat([]byte("x"), 100), clk: clk})
+
+	buf := make([]byte, 1)
+	for i := 0; i < 55; i++ {
+		r.Read(buf)
+	}
+	if c.current != 50 {
+		t.Errorf("current = %d, want 50 after batched flushes", c.current)
+	}
+	r.Close()
+	if c.current != 55 {
+		t.Errorf("current = %d, want 55 after Close", c.current)
+	}
+	io.Copy(io.Discard, r)
+	if c.current != 100 {
+		t.Errorf("current = %d, want 100 at EOF", c.current)
+	}
+	if renders > 12 {
+		t.Errorf("renders = %d, want at most one per flush", renders)
+	}
+}
+
+func TestLabeledProxies(t *testing.T) {
+	c, _, _ := newTestBar(20, 40)
+	c.SetShowBar(false)
+	if _, err := io.Copy(io.Discard, c.NewLabeledProxyReader(strings.NewReader("0123456789"), "a.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := c.Render(), "10/20 a.txt"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+
+	var dst bytes.Buffer
+	w := c.NewLabeledProxyWriter(&dst, "b.txt")
+	if _, err := io.Copy(w, strings.NewReader("01234")); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := c.Render(), "15/20 b.txt"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	if dst.String() != "01234" {
+		t.Errorf("dst = %q, want data forwarded", dst.String())
+	}
+}
+
+func TestProgressBarReader(t *testing.T) {
+	c, r := ProgressBarReader(strings.NewReader("0123456789"), 10)
+	c.SetWidth(40).SetOutput(io.Discard)
+	if c.unit != UnitBytes || !c.showSpeed || !c.showLastTime {
+		t.Error("reader bar should default to bytes, speed and ETA")
+	}
+	if n, err := io.Copy(io.Discard, r); err != nil || n != 10 {
+		t.Fatalf("io.Copy = %d, %v", n, err)
+	}
+	if !c.IsComplete() {
+		t.Error("bar should be complete after reading everything")
+	}
+}
+
+// 内存中的 io.WriterAt
+type memWriterAt struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (m *memWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copy(m.buf[off:], p)
+	return len(p), nil
+}
+
+func TestProxyWriterAtCountsNewBytes(t *testing.T) {
+	c, _, _ := newTestBar(100, 40)
+	w := c.NewProxyWriterAt(&memWriterAt{buf: make([]byte, 100)})
+	chunk := make([]byte, 10)
+	w.WriteAt(chunk, 0)
+	w.WriteAt(chunk, 20)
+	w.WriteAt(chunk, 5)  // 与 [0,10) 重叠 5 字节
+	w.WriteAt(chunk, 0)  // 完全重复
+	w.WriteAt(chunk, 10) // 填补 [10,20) 的剩余部分
+	if got := c.Snapshot().Current; got != 30 {
+		t.Errorf("Current = %d, want 30", got)
+	}
+
+	var wg sync.WaitGroup
+	for i := 3; i < 10; i++ {
+		wg.Add(1)
+		go func(off int64) {
+			defer wg.Done()
+			w.WriteAt(chunk, off)
+		}(int64(i * 10))
+	}
+	wg.Wait()
+	if !c.IsComplete() {
+		t.Errorf("Current = %d, want complete", c.Snapshot().Current)
+	}
+}
+
+func TestProxyReaderCompletesWithoutEOF(t *testing.T) {
+	// 创建 Reader 之后才设置刷新间隔，也按间隔累计；读满总数时立即提交，无需 EOF 或 Close
+	c, r := ProgressBarReader(strings.NewReader(strings.Repeat("x", 200)), 100)
+	c.SetOutput(io.Discard).SetRefreshInterval(time.Second)
+	if _, err := io.CopyN(io.Discard, r, 60); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Snapshot().Current; got != 0 {
+		t.Errorf("current = %d, want reads batched by the refresh interval", got)
+	}
+	if _, err := io.CopyN(io.Discard, r, 40); err != nil {
+		t.Fatal(err)
+	}
+	if !c.IsComplete() {
+		t.Errorf("current = %d, want completion once the total is read", c.Snapshot().Current)
+	}
+	select {
+	case <-c.Done():
+	default:
+		t.Error("Done should be closed")
+	}
+}
@@ -0,0 +1,77 @@
+package ProgressBar
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// 进程内共享的进度条登记处：开启后新建的进度条都登记在这里，
+// 原地刷新时由它统一输出，多个进度条按首次输出的顺序上下排列，不会互相覆盖
+type registry struct {
+	mu    sync.Mutex
+	bars  []*Config          // 按首次输出排列的进度条
+	lines map[*Config]string // 每个进度条最近一次的输出
+	done  map[*Config]bool   // 已结束的进度条
+	drawn int                // 当前已输出的行数
+}
+
+var (
+	globalMu       sync.Mutex
+	globalRegistry *registry // nil 表示未开启
+)
+
+// EnableGlobalRegistry 开启后，之后创建的进度条自动登记到进程内共享的登记处，
+// 即使在代码各处分别创建，原地刷新时也会串行输出并自动上下排列；默认关闭。
+// 登记的进度条应输出到同一个终端，全部结束后换行，之后的进度条重新开始排列
+func EnableGlobalRegistry(flag bool) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	if !flag {
+		globalRegistry = nil
+	} else if globalRegistry == nil {
+		globalRegistry = &registry{lines: map[*Config]string{}, done: map[*Config]bool{}}
+	}
+}
+
+// 当前的登记处，未开启时返回 nil
+func currentRegistry() *registry {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	return globalRegistry
+}
+
+// 记录 c 的最新一行并重绘整组进度条，返回写入错误；全部结束后换行并清空，调用方需持有 c 的锁
+func (r *registry) update(c *Config, out io.Writer, line string, done bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.lines[c]; !ok {
+		r.bars = append(r.bars, c)
+	}
+	r.lines[c] = line
+	if done {
+		r.done[c] = true
+	}
+
+	// 光标停在最后一行末尾，先回到第一行再逐行覆盖
+	var b strings.Builder
+	if r.drawn > 1 {
+		fmt.Fprintf(&b, "\x1b[%dA", r.drawn-1)
+	}
+	for i, bar := range r.bars {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("\r" + r.lines[bar] + "\x1b[K")
+	}
+	r.drawn = len(r.bars)
+	if len(r.done) == len(r.bars) {
+		b.WriteString("\n")
+		r.bars, r.drawn = nil, 0
+		r.lines = map[*Config]string{}
+		r.done = map[*Config]bool{}
+	}
+	_, err := io.WriteString(out, b.String())
+	return err
+}
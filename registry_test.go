@@ -0,0 +1,42 @@
+package ProgressBar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGlobalRegistryStacksBars(t *testing.T) {
+	EnableGlobalRegistry(true)
+	defer EnableGlobalRegistry(false)
+
+	out := &bytes.Buffer{}
+	a, _, _ := newTestBar(10, 20)
+	b, _, _ := newTestBar(10, 20)
+	a.SetOutput(out)
+	b.SetOutput(out)
+
+	a.Update(5)
+	b.Update(2)
+	out.Reset()
+	a.Update(6)
+	want := "\x1b[1A\r[======>    ]  6/10\x1b[K\n\r[==>        ]  2/10\x1b[K"
+	if got := out.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	out.Reset()
+	a.Update(10)
+	b.Update(10)
+	if got := out.String(); !strings.HasSuffix(got, "10/10\x1b[K\n") {
+		t.Errorf("all bars finished should end with a newline: %q", got)
+	}
+
+	c, _, _ := newTestBar(10, 20)
+	c.SetOutput(out)
+	out.Reset()
+	c.Update(1)
+	if got, want := out.String(), "\r[=>         ]  1/10\x1b[K"; got != want {
+		t.Errorf("new bar after reset: output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,53 @@
+package ProgressBar
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// SetAutoWidth 是否跟随终端大小变化调整宽度，默认开启；
+// 关闭后停止后台监听，保留当前宽度
+func (c *Config) SetAutoWidth(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if flag {
+		c.watchResize()
+	} else {
+		c.stopResize()
+	}
+	return c
+}
+
+// 启动后台监听 SIGWINCH，已在监听时不重复启动，调用方需持有锁(构造时除外)
+func (c *Config) watchResize() {
+	if c.resizeStop != nil {
+		return
+	}
+	sigwinch := make(chan os.Signal, 1)
+	stop := make(chan struct{})
+	c.resizeStop = stop
+	signal.Notify(sigwinch, syscall.SIGWINCH)
+
+	go func() {
+		defer signal.Stop(sigwinch)
+		for {
+			select {
+			case <-sigwinch:
+				c.mu.Lock()
+				c.resize(terminalWidthOr(c.out, c.width))
+				c.unlock()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// 停止监听窗口大小变化，调用方需持有锁
+func (c *Config) stopResize() {
+	if c.resizeStop != nil {
+		close(c.resizeStop)
+		c.resizeStop = nil
+	}
+}
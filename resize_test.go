@@ -0,0 +1,56 @@
+package ProgressBar
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAutoWidth(t *testing.T) {
+	c := ProgressBar(10)
+	if c.resizeStop == nil {
+		t.Fatal("constructor should start the resize watcher")
+	}
+	width := c.width
+	stop := c.resizeStop
+	c.SetAutoWidth(false)
+	select {
+	case <-stop:
+	default:
+		t.Fatal("SetAutoWidth(false) should stop the watcher")
+	}
+	if c.resizeStop != nil || c.width != width {
+		t.Error("watcher should be cleared and width kept")
+	}
+
+	c.SetAutoWidth(true)
+	if c.resizeStop == nil {
+		t.Error("SetAutoWidth(true) should restart the watcher")
+	}
+	c.SetWidth(50)
+	if c.resizeStop != nil {
+		t.Error("SetWidth should stop the watcher")
+	}
+}
+
+func TestUsableWidth(t *testing.T) {
+	tests := []struct {
+		width    int
+		err      error
+		fallback int
+		want     int
+	}{
+		{80, nil, defaultWidth, 80},
+		{minWidth, nil, defaultWidth, minWidth},
+		{2, nil, defaultWidth, defaultWidth},
+		{0, nil, defaultWidth, defaultWidth},
+		{80, errors.New("not a terminal"), defaultWidth, defaultWidth},
+		// 运行中偶发失败时保留上一次的有效宽度
+		{0, errors.New("not a terminal"), 132, 132},
+		{1, nil, 132, 132},
+	}
+	for _, tt := range tests {
+		if got := usableWidth(tt.width, tt.err, tt.fallback); got != tt.want {
+			t.Errorf("usableWidth(%d, %v, %d) = %d, want %d", tt.width, tt.err, tt.fallback, got, tt.want)
+		}
+	}
+}
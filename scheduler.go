@@ -0,0 +1,104 @@
+package ProgressBar
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetRenderDelta 进度变化(与上次输出相比)达到 n 才输出，减少大量微小更新时的输出；
+// 0 或负数表示不限制。完成时的最后一帧和窗口变化后的重绘不受影响
+func (c *Config) SetRenderDelta(n int64) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	c.renderDelta = n
+	return c
+}
+
+// SetMaxLineWrites 设置最多输出的帧数，超出后不再刷新(进度仍正常累计)，并输出一次警告，
+// 防止没有限流的死循环刷屏；0 或负数表示不限制(默认)
+func (c *Config) SetMaxLineWrites(n int) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	c.maxWrites = n
+	return c
+}
+
+// 输出帧数是否已达上限，第一次达到时另起一行输出警告，调用方需持有锁
+func (c *Config) writesCapped() bool {
+	if c.maxWrites == 0 || c.writes < c.maxWrites {
+		return false
+	}
+	if !c.capWarned {
+		c.capWarned = true
+		if !c.plain() && c.lastLineWidth > 0 {
+			c.write("\n")
+			c.lastLineWidth = 0
+		}
+		c.printMessage(fmt.Sprintf("ProgressBar: stopped redrawing after %d writes (SetMaxLineWrites limit)", c.writes))
+	}
+	return true
+}
+
+// 所有输出的统一入口(更新、窗口变化、自动重绘、Draw)，按以下顺序决定是否输出一帧：
+//   - 已结束、已输出完成的最后一帧或输出帧数达到上限(SetMaxLineWrites)：不输出，
+//     达到完成阈值后继续更新只累计进度，完成行和换行只输出一次
+//   - force(Draw)或已完成：总是输出，完成时的最后一帧不会被跳过
+//   - 只在变化时刷新(SetRefreshOnlyOnStateChange)：没有变化且未到停滞时钟间隔时跳过
+//   - 进度变化量(SetRenderDelta)：变化不足时跳过
+//   - 时间限流(SetRefreshInterval/SetRefreshFPS，逐行输出时默认 1 秒)：距上次输出不足间隔时跳过
+//
+// 被跳过的变化保持待输出状态(见 hasPending)，下一次允许输出时一并显示；调用方需持有锁
+func (c *Config) maybeRender(force bool) {
+	if c.finished || c.donePainted || c.writesCapped() {
+		return
+	}
+	if !force && !c.complete() && !c.shouldRender() {
+		return
+	}
+	c.lastRender = c.now()
+	c.writes++
+	c.paint()
+}
+
+// 是否有尚未输出的变化：进度、总数或窗口大小与上次输出时不同，调用方需持有锁
+func (c *Config) hasPending() bool {
+	return c.resized || c.current != c.paintedCurrent || c.total != c.paintedTotal
+}
+
+// 按各项策略判断本次是否应该输出，调用方需持有锁
+func (c *Config) shouldRender() bool {
+	now := c.now()
+	if c.onlyOnChange && !c.hasPending() {
+		if c.stallClock <= 0 || now.Sub(c.lastRender) < c.stallClock {
+			return false
+		}
+	}
+	if c.renderDelta > 0 && !c.resized && !c.lastRender.IsZero() {
+		delta := c.current - c.paintedCurrent
+		if delta < 0 {
+			delta = -delta
+		}
+		if delta < c.renderDelta {
+			return false
+		}
+	}
+	interval := c.renderInterval()
+	if interval > 0 && !c.lastRender.IsZero() && now.Sub(c.lastRender) < interval {
+		return false
+	}
+	return true
+}
+
+// 实际生效的最小输出间隔：SetRefreshInterval 的设置，逐行输出时默认 1 秒，调用方需持有锁
+func (c *Config) renderInterval() time.Duration {
+	if c.refreshInterval == 0 && c.plain() {
+		return defaultPlainInterval
+	}
+	return c.refreshInterval
+}
@@ -0,0 +1,106 @@
+package ProgressBar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// 返回输出中的帧数(不含清除旧行用的 \r)
+func frames(s string) int {
+	return strings.Count(s, "\r[")
+}
+
+func TestSchedulerTimeThrottle(t *testing.T) {
+	c, clk, buf := newTestBar(100, 40)
+	c.SetRefreshFPS(10) // 100ms
+	c.Update(1)
+	clk.advance(50)
+	c.Update(2) // 不足间隔，跳过
+	if got := frames(buf.String()); got != 1 {
+		t.Fatalf("frames = %d, want 1", got)
+	}
+	clk.advance(50)
+	c.Tick() // 待输出的变化在下一次允许时显示
+	if got := frames(buf.String()); got != 2 || !strings.HasSuffix(buf.String(), "  2/100") {
+		t.Errorf("pending change not rendered: %q", buf.String())
+	}
+}
+
+func TestSchedulerRenderDelta(t *testing.T) {
+	c, _, buf := newTestBar(100, 40)
+	c.SetRenderDelta(10)
+	c.Update(1) // 第一帧总是输出
+	c.Update(5)
+	c.Update(10)
+	if got := frames(buf.String()); got != 1 {
+		t.Fatalf("frames = %d, want 1", got)
+	}
+	c.Update(11)
+	if got := frames(buf.String()); got != 2 {
+		t.Errorf("frames = %d, want 2", got)
+	}
+	c.Update(100) // 完成帧不受变化量限制
+	if !strings.HasSuffix(buf.String(), "100/100\n") {
+		t.Errorf("completion frame missing: %q", buf.String())
+	}
+}
+
+func TestSchedulerOnStateChange(t *testing.T) {
+	c, clk, buf := newTestBar(100, 40)
+	c.SetRefreshOnlyOnStateChange(true, 0)
+	c.Update(1)
+	c.Update(1) // 没有变化
+	clk.advance(1000)
+	c.Tick()
+	if got := frames(buf.String()); got != 1 {
+		t.Fatalf("frames = %d, want 1", got)
+	}
+	c.resize(30) // 窗口变化视为状态变化
+	if got := frames(buf.String()); got != 2 {
+		t.Errorf("frames after resize = %d, want 2", got)
+	}
+}
+
+func TestSchedulerForceAndSeal(t *testing.T) {
+	c, _, buf := newTestBar(100, 40)
+	c.SetRefreshInterval(time.Hour)
+	c.Update(1)
+	c.Update(2)
+	c.Draw() // 忽略限流
+	if got := frames(buf.String()); got != 2 {
+		t.Fatalf("frames = %d, want 2", got)
+	}
+	c.Finish()
+	n := buf.Len()
+	c.Draw()
+	c.Tick()
+	if buf.Len() != n {
+		t.Errorf("render after Finish: %q", buf.String()[n:])
+	}
+}
+
+func TestMaxLineWrites(t *testing.T) {
+	c, _, buf := newTestBar(100, 40)
+	c.SetMaxLineWrites(3)
+	for i := int64(1); i <= 10; i++ {
+		c.Update(i)
+	}
+	out := buf.String()
+	if got := frames(out); got != 3 {
+		t.Errorf("frames = %d, want 3", got)
+	}
+	if n := strings.Count(out, "SetMaxLineWrites"); n != 1 {
+		t.Errorf("warning written %d times, want once: %q", n, out)
+	}
+	if !strings.Contains(out, "  3/100\nProgressBar:") {
+		t.Errorf("warning should start on a new line: %q", out)
+	}
+	if c.Snapshot().Current != 10 {
+		t.Errorf("current = %d, want progress to keep counting", c.Snapshot().Current)
+	}
+	c.Draw()
+	if frames(buf.String()) != 3 {
+		t.Error("Draw should not render past the cap")
+	}
+}
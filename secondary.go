@@ -0,0 +1,54 @@
+package ProgressBar
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Counter 附属计数器，有独立的总数和单位，与主进度条显示在同一行
+// 主进度条仍决定进度条本身的长度
+type Counter struct {
+	bar     *Config
+	current int64
+	total   int64
+	unit    Unit
+}
+
+// SecondaryCounter 添加一个附属计数器，按添加顺序显示在数量字段之后
+func (c *Config) SecondaryCounter(total int64, unit Unit) *Counter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := &Counter{bar: c, total: total, unit: unit}
+	c.counters = append(c.counters, s)
+	return s
+}
+
+// Add 增加附属计数器的值并刷新进度条
+func (s *Counter) Add(delta int64) {
+	s.bar.mu.Lock()
+	defer s.bar.unlock()
+	if s.bar.finished {
+		return
+	}
+	s.current += delta
+	s.bar.changed()
+}
+
+// Update 设置附属计数器的值并刷新进度条
+func (s *Counter) Update(current int64) {
+	s.bar.mu.Lock()
+	defer s.bar.unlock()
+	if s.bar.finished {
+		return
+	}
+	s.current = current
+	s.bar.changed()
+}
+
+// 格式化为 x/y
+func (s *Counter) String() string {
+	if s.unit == UnitBytes {
+		return fmt.Sprintf("%s/%s", strings.TrimSpace(s.bar.sizeStr(s.current)), strings.TrimSpace(s.bar.sizeStr(s.total)))
+	}
+	return fmt.Sprintf("%d/%d", s.current, s.total)
+}
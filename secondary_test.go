@@ -0,0 +1,21 @@
+package ProgressBar
+
+import "testing"
+
+func TestSecondaryCounter(t *testing.T) {
+	c, _, _ := newTestBar(4096, 60)
+	c.SetUnit(UnitBytes)
+	files := c.SecondaryCounter(3, UnitRaw)
+	c.Update(2048)
+	files.Add(1)
+	files.Add(1)
+
+	want := "[================>                ]    2.0 KB/   4.0 KB 2/3"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	files.Update(3)
+	if files.current != 3 || c.current != 2048 {
+		t.Errorf("counters should advance independently: %d, %d", files.current, c.current)
+	}
+}
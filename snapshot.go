@@ -0,0 +1,131 @@
+package ProgressBar
+
+import (
+	"math"
+	"time"
+)
+
+// Snapshot 某一时刻的进度状态
+type Snapshot struct {
+	Current  int64
+	Total    int64
+	Percent  float64       // 百分比，0-100
+	Speed    float64       // 最近一次测得的每秒数量(字节单位时为字节)，未采样时为 0
+	AvgSpeed float64       // 全程平均每秒数量
+	Elapsed  time.Duration // 已用时间
+	ETA      time.Duration // 剩余时间，无法估算时为 -1
+	Done     bool          // 是否已完成或已调用 Finish
+}
+
+// SnapshotCompare Snapshot.Equal 的比较选项，零值只比较与时间无关的字段
+type SnapshotCompare struct {
+	Timing    bool          // 是否比较 Elapsed、ETA
+	Speed     bool          // 是否比较 Speed、AvgSpeed
+	Tolerance time.Duration // 比较 Elapsed、ETA 时允许的误差
+}
+
+// Equal 比较两个状态，默认忽略随时间抖动的耗时、剩余时间和速度，方便在 OnRender 等回调的测试中使用
+func (s Snapshot) Equal(o Snapshot, opts SnapshotCompare) bool {
+	if s.Current != o.Current || s.Total != o.Total || s.Percent != o.Percent || s.Done != o.Done {
+		return false
+	}
+	if opts.Speed && (s.Speed != o.Speed || s.AvgSpeed != o.AvgSpeed) {
+		return false
+	}
+	if opts.Timing {
+		within := func(a, b time.Duration) bool {
+			d := a - b
+			return d <= opts.Tolerance && d >= -opts.Tolerance
+		}
+		return within(s.Elapsed, o.Elapsed) && within(s.ETA, o.ETA)
+	}
+	return true
+}
+
+// Snapshot 返回当前状态，各字段在同一把锁下读取，彼此一致
+func (c *Config) Snapshot() Snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.snapshot(c.now())
+}
+
+// Percent 返回当前百分比，限制在 0-100；总数为 0 或未知时返回 0
+func (c *Config) Percent() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.percent()
+}
+
+// 计算百分比，显示和 Percent 共用，调用方需持有锁
+func (c *Config) percent() float64 {
+	if c.total <= 0 || c.current <= 0 {
+		return 0
+	}
+	// 达到或超出总数时直接取 100%，避免浮点误差显示为 99.9%
+	if c.current >= c.total {
+		return 100
+	}
+	p := float64(c.current) / float64(c.total) * 100
+	if math.IsNaN(p) || p < 0 {
+		return 0
+	}
+	return math.Min(p, 100)
+}
+
+// 计算 now 时刻的状态，百分比限制在 0-100，调用方需持有锁
+func (c *Config) snapshot(now time.Time) Snapshot {
+	s := Snapshot{
+		Current: c.current,
+		Total:   c.total,
+		Speed:   c.speed,
+		Elapsed: now.Sub(c.startTime),
+		ETA:     -1,
+		Done:    c.finished || c.complete(),
+	}
+	s.Percent = c.percent()
+	if rate, ok := c.windowRate(); ok && c.total > 0 {
+		// 按窗口内的速度估算，窗口内没有进展时无法估算
+		if remaining := c.total - c.current; remaining <= 0 {
+			s.ETA = 0
+		} else if rate > 0 {
+			s.ETA = time.Duration(float64(remaining) / rate * float64(time.Second))
+		}
+	} else if s.Percent > 0 {
+		s.ETA = time.Duration(float64(s.Elapsed)*(100/s.Percent) - float64(s.Elapsed))
+	}
+	if s.Elapsed > 0 {
+		s.AvgSpeed = float64(c.current) / s.Elapsed.Seconds()
+	}
+	return s
+}
+
+// SetOnRender 设置每次输出(限流后)之后的回调，回调在锁外执行，可以调用 Snapshot 等读取方法；
+// 不要在回调中调用 Update、Add 等修改进度条的方法，否则每次输出都会再次触发回调，可能无限递归
+func (c *Config) SetOnRender(fn func(Snapshot)) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onRender = fn
+	return c
+}
+
+// SetOnFirstRender 设置第一次输出进度条之前调用一次的回调，如在进度条上方输出标题行；
+// 为保证先于进度条输出，回调在持有锁时调用，其中不要调用进度条的方法
+func (c *Config) SetOnFirstRender(fn func()) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onFirstRender = fn
+	return c
+}
+
+// 释放锁，然后依次执行期间积累的渲染回调
+func (c *Config) unlock() {
+	fn, pending := c.onRender, c.pending
+	c.pending = nil
+	c.mu.Unlock()
+	if fn == nil {
+		return
+	}
+	for _, s := range pending {
+		fn(s)
+	}
+}
@@ -0,0 +1,125 @@
+package ProgressBar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOnRender(t *testing.T) {
+	c, clk, _ := newTestBar(100, 40)
+	var got []Snapshot
+	c.SetRefreshInterval(time.Second).ShowSpeed(true)
+	c.SetOnRender(func(s Snapshot) {
+		got = append(got, s)
+		c.IsComplete() // 回调中调用进度条方法不能死锁
+	})
+
+	c.Update(10)
+	clk.advance(500)
+	c.Update(20) // 被限流，不回调
+	clk.advance(500)
+	c.Update(25)
+
+	if len(got) != 2 {
+		t.Fatalf("callbacks = %d, want 2", len(got))
+	}
+	want := Snapshot{Current: 25, Total: 100, Percent: 25, Speed: 15, AvgSpeed: 25, Elapsed: time.Second, ETA: 3 * time.Second}
+	if got[1] != want {
+		t.Errorf("snapshot = %+v, want %+v", got[1], want)
+	}
+	if got[0].Current != 10 {
+		t.Errorf("first snapshot current = %d, want 10", got[0].Current)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	c, clk, _ := newTestBar(200, 40)
+	c.ShowSpeed(true)
+	c.Update(0)
+	clk.advance(2000)
+	c.Update(50)
+	clk.advance(2000)
+
+	want := Snapshot{Current: 50, Total: 200, Percent: 25, Speed: 25, AvgSpeed: 12.5, Elapsed: 4 * time.Second, ETA: 12 * time.Second}
+	if got := c.Snapshot(); got != want {
+		t.Errorf("Snapshot() = %+v, want %+v", got, want)
+	}
+
+	c.Update(200)
+	if s := c.Snapshot(); !s.Done || s.Percent != 100 || s.ETA != 0 {
+		t.Errorf("completed snapshot = %+v", s)
+	}
+
+	c, _, _ = newTestBar(0, 40)
+	if s := c.Snapshot(); s.Percent != 0 || s.ETA != -1 || s.Done {
+		t.Errorf("unknown-total snapshot = %+v", s)
+	}
+}
+
+func TestOnFirstRender(t *testing.T) {
+	c, _, buf := newTestBar(10, 20)
+	calls := 0
+	c.SetOnFirstRender(func() {
+		calls++
+		buf.WriteString("header\n")
+	})
+	c.Render() // Render 只生成字符串，不算输出
+	c.Update(1)
+	c.Update(2)
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+	if !strings.HasPrefix(buf.String(), "header\n\r[") {
+		t.Errorf("output = %q, want header before the bar", buf.String())
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		current, total int64
+		want           float64
+	}{
+		{0, 0, 0},
+		{5, 0, 0},
+		{0, 100, 0},
+		{42, 100, 42},
+		{110, 100, 100},
+		{1, 4, 25},
+	}
+	for _, tt := range tests {
+		c, _, _ := newTestBar(tt.total, 40)
+		c.Update(tt.current)
+		got := c.Percent()
+		if got != tt.want || got != c.Snapshot().Percent {
+			t.Errorf("%d/%d: Percent() = %v, Snapshot().Percent = %v, want %v",
+				tt.current, tt.total, got, c.Snapshot().Percent, tt.want)
+		}
+	}
+}
+
+func TestSnapshotEqual(t *testing.T) {
+	c, clk, _ := newTestBar(100, 40)
+	c.Update(40)
+	a := c.Snapshot()
+	clk.advance(1500)
+	b := c.Snapshot()
+
+	if !a.Equal(b, SnapshotCompare{}) {
+		t.Errorf("snapshots differing only in timing should be equal: %+v vs %+v", a, b)
+	}
+	if a.Equal(b, SnapshotCompare{Timing: true}) {
+		t.Error("Timing should compare elapsed and ETA")
+	}
+	if !a.Equal(b, SnapshotCompare{Timing: true, Tolerance: 3 * time.Second}) {
+		t.Error("differences within Tolerance should be ignored")
+	}
+	if a.Equal(b, SnapshotCompare{Speed: true}) {
+		t.Error("Speed should compare the average speed")
+	}
+
+	c.Update(41)
+	if a.Equal(c.Snapshot(), SnapshotCompare{}) {
+		t.Error("snapshots with different progress should not be equal")
+	}
+}
@@ -0,0 +1,59 @@
+package ProgressBar
+
+// 走势图默认采样数
+const defaultSparkSize = 8
+
+// 走势图字符，从低到高
+var sparkRunes = []rune("▁▂▃▄▅▆▇█")
+
+// ShowSparkline 是否显示最近速度的走势图
+func (c *Config) ShowSparkline(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.showSparkline = flag
+	return c
+}
+
+// SetSparklineSize 设置走势图保留的采样数
+func (c *Config) SetSparklineSize(n int) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n < 1 {
+		n = 1
+	}
+	c.sparkSize = n
+	c.samples = nil
+	c.samplePos = 0
+	return c
+}
+
+// 写入一个速度采样，缓冲区满后覆盖最旧的采样
+func (c *Config) pushSample(v float64) {
+	if len(c.samples) < c.sparkSize {
+		c.samples = append(c.samples, v)
+		return
+	}
+	c.samples[c.samplePos] = v
+	c.samplePos = (c.samplePos + 1) % c.sparkSize
+}
+
+// 按从旧到新的顺序渲染走势图，以最大值为满格
+func (c *Config) sparkline() string {
+	n := len(c.samples)
+	var max float64
+	for _, v := range c.samples {
+		if v > max {
+			max = v
+		}
+	}
+	out := make([]rune, 0, n)
+	for i := 0; i < n; i++ {
+		v := c.samples[(c.samplePos+i)%n]
+		level := 0
+		if max > 0 && v > 0 {
+			level = int(v / max * float64(len(sparkRunes)-1))
+		}
+		out = append(out, sparkRunes[level])
+	}
+	return string(out)
+}
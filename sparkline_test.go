@@ -0,0 +1,29 @@
+package ProgressBar
+
+import "testing"
+
+func TestSparklineRing(t *testing.T) {
+	c, _, _ := newTestBar(100, 40)
+	c.SetSparklineSize(4)
+	for _, v := range []float64{1, 2, 4, 8, 6, 3} {
+		c.pushSample(v)
+	}
+	// 保留最后4个采样: 4 8 6 3
+	if got, want := c.sparkline(), "▄█▆▃"; got != want {
+		t.Errorf("sparkline() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderSparkline(t *testing.T) {
+	c, clk, _ := newTestBar(100, 60)
+	c.ShowSparkline(true)
+	c.Render()
+	for _, step := range []int64{10, 20, 10} {
+		clk.advance(1000)
+		c.current += step
+		c.Render()
+	}
+	if got, want := c.sparkline(), "▄█▄"; got != want {
+		t.Errorf("sparkline() = %q, want %q", got, want)
+	}
+}
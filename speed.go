@@ -0,0 +1,133 @@
+package ProgressBar
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// SpeedUnit 速度字段的单位，可以与数量的单位不同
+type SpeedUnit int
+
+const (
+	SpeedAuto         SpeedUnit = iota // 0: 跟随数量单位(默认)
+	SpeedItems                         // 1: 每秒数量，如 12.00 items/s
+	SpeedBytes                         // 2: 每秒字节，1024 进制
+	SpeedBytesDecimal                  // 3: 每秒字节，1000 进制
+	SpeedPercent                       // 4: 每秒完成的百分比，如 0.75 %/s，总数未知时按数量显示
+	SpeedBits                          // 5: 每秒比特(数量按字节计)，1000 进制，如 12.5 Mbps
+)
+
+// SetSpeedUnit 设置速度字段的单位
+func (c *Config) SetSpeedUnit(unit SpeedUnit) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.speedUnit = unit
+	return c
+}
+
+// SetSpeedDecimals 设置按数量显示速度时的小数位数，默认 2
+func (c *Config) SetSpeedDecimals(n int) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	c.speedDecimals = n
+	return c
+}
+
+// SetSpeedRightAlign 按数量显示速度时是否右对齐到固定宽度(4 位整数加小数)，默认开启
+func (c *Config) SetSpeedRightAlign(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.speedNoAlign = !flag
+	return c
+}
+
+// SetMinSampleInterval 设置两次速度采样之间的最小间隔，间隔内的进度累积到下一次采样，
+// 避免快速循环中极小的时间差导致速度剧烈跳动；0 表示每次渲染都采样
+func (c *Config) SetMinSampleInterval(d time.Duration) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d < 0 {
+		d = 0
+	}
+	c.minSample = d
+	return c
+}
+
+// 按数量显示的速度数值
+func (c *Config) speedNumber(speed float64) string {
+	if c.speedNoAlign {
+		return fmt.Sprintf("%.*f", c.speedDecimals, speed)
+	}
+	width := 4
+	if c.speedDecimals > 0 {
+		width += c.speedDecimals + 1
+	}
+	return fmt.Sprintf("%*.*f", width, c.speedDecimals, speed)
+}
+
+// 实际使用的速度单位
+func (c *Config) effectiveSpeedUnit() SpeedUnit {
+	if c.speedUnit == SpeedPercent && c.total <= 0 {
+		return SpeedItems
+	}
+	if c.speedUnit != SpeedAuto {
+		return c.speedUnit
+	}
+	if c.unit == UnitBytes {
+		return SpeedBytes
+	}
+	return SpeedItems
+}
+
+// 格式化速度(每秒数量)，不含括号
+func (c *Config) formatSpeed(speed float64) string {
+	switch c.effectiveSpeedUnit() {
+	case SpeedBytes:
+		return formatSize(int64(speed), 1024, c.iecLabels) + "/s"
+	case SpeedBytesDecimal:
+		return formatSize(int64(speed), 1000, c.iecLabels) + "/s"
+	case SpeedPercent:
+		return fmt.Sprintf("%s %%/s", c.speedNumber(speed*100/float64(c.total)))
+	case SpeedBits:
+		return formatBitRate(speed * 8)
+	}
+	return fmt.Sprintf("%s %s/s", c.speedNumber(speed), c.labels.Items)
+}
+
+// 速度字段的最大常见宽度
+func (c *Config) speedFieldWidth() int {
+	switch c.effectiveSpeedUnit() {
+	case SpeedBytes, SpeedBytesDecimal:
+		// 后缀随 SetIECLabels 变化(KiB 比 KB 宽一列)，按实际使用的后缀计算
+		base := int64(1024)
+		if c.effectiveSpeedUnit() == SpeedBytesDecimal {
+			base = 1000
+		}
+		_, suffix := sizeScale(base*base, base, c.iecLabels)
+		return displayWidth(fmt.Sprintf(" (%6.1f %s/s)", 1023.9, suffix))
+	case SpeedBits:
+		return displayWidth(" (999.9 Kbps)")
+	case SpeedPercent:
+		return displayWidth(fmt.Sprintf(" (%s %%/s)", c.speedNumber(100)))
+	}
+	return displayWidth(fmt.Sprintf(" (%s %s/s)", c.speedNumber(9999), c.labels.Items))
+}
+
+// 辅助函数：按 1000 进制将每秒比特数转换为 bps、Kbps、Mbps、Gbps 等；
+// 按保留一位小数后的值选择单位，避免出现 1000.0 Kbps
+func formatBitRate(bits float64) string {
+	if bits < 999.5 {
+		return fmt.Sprintf("%3d bps", int64(math.Round(max(bits, 0))))
+	}
+	const prefixes = "KMGTPE"
+	div, exp := 1000.0, 0
+	for math.Round(bits/div*10) >= 10000 && exp < len(prefixes)-1 {
+		div *= 1000
+		exp++
+	}
+	return fmt.Sprintf("%5.1f %cbps", bits/div, prefixes[exp])
+}
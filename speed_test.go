@@ -0,0 +1,197 @@
+package ProgressBar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatSpeed(t *testing.T) {
+	tests := []struct {
+		unit      Unit
+		speedUnit SpeedUnit
+		speed     float64
+		want      string
+	}{
+		{UnitRaw, SpeedAuto, 12.5, "  12.50 items/s"},
+		{UnitBytes, SpeedAuto, 2048, "   2.0 KB/s"},
+		{UnitBytes, SpeedAuto, 512, "512 B/s"},
+		{UnitBytes, SpeedBytesDecimal, 2_500_000, "   2.5 MB/s"},
+		{UnitBytes, SpeedItems, 3, "   3.00 items/s"},
+		{UnitRaw, SpeedBytes, 1 << 20, "   1.0 MB/s"},
+	}
+	for _, tt := range tests {
+		c, _, _ := newTestBar(100, 40)
+		c.SetUnit(tt.unit).SetSpeedUnit(tt.speedUnit)
+		if got := c.formatSpeed(tt.speed); got != tt.want {
+			t.Errorf("formatSpeed(%v) unit=%d speedUnit=%d = %q, want %q", tt.speed, tt.unit, tt.speedUnit, got, tt.want)
+		}
+	}
+}
+
+func TestRenderByteSpeed(t *testing.T) {
+	c, clk, _ := newTestBar(1<<20, 60)
+	c.SetUnit(UnitBytes).ShowSpeed(true)
+	c.Render()
+	clk.advance(1000)
+	c.current = 4096
+	want := "[>                      ]    4.0 KB/   1.0 MB (   4.0 KB/s)"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatSizeLabels(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		unit  int64
+		iec   bool
+		want  string
+	}{
+		{512, 1024, false, "512 B"},
+		{2048, 1024, false, "   2.0 KB"},
+		{2048, 1024, true, "   2.0 KiB"},
+		{3 << 30, 1024, true, "   3.0 GiB"},
+		{2000, 1000, false, "   2.0 KB"},
+		{2000, 1000, true, "   2.0 kB"},
+		{2_000_000, 1000, true, "   2.0 MB"},
+	}
+	for _, tt := range tests {
+		if got := formatSize(tt.bytes, tt.unit, tt.iec); got != tt.want {
+			t.Errorf("formatSize(%d, %d, %v) = %q, want %q", tt.bytes, tt.unit, tt.iec, got, tt.want)
+		}
+	}
+}
+
+func TestIECLabelsRender(t *testing.T) {
+	c, _, _ := newTestBar(1<<20, 50)
+	c.SetUnit(UnitBytes).SetIECLabels(true)
+	c.current = 512 * 1024
+	want := "[============>            ]  512.0 KiB/   1.0 MiB"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestSpeedDecimals(t *testing.T) {
+	c, _, _ := newTestBar(100, 40)
+	if got, want := c.formatSpeed(3.14159), "   3.14 items/s"; got != want {
+		t.Errorf("default = %q, want %q", got, want)
+	}
+	c.SetSpeedDecimals(0)
+	if got, want := c.formatSpeed(1234.6), "1235 items/s"; got != want {
+		t.Errorf("0 decimals = %q, want %q", got, want)
+	}
+	c.SetSpeedDecimals(4)
+	if got, want := c.formatSpeed(0.125), "   0.1250 items/s"; got != want {
+		t.Errorf("4 decimals = %q, want %q", got, want)
+	}
+	c.SetSpeedRightAlign(false)
+	if got, want := c.formatSpeed(0.125), "0.1250 items/s"; got != want {
+		t.Errorf("unaligned = %q, want %q", got, want)
+	}
+	if got, want := c.speedFieldWidth(), len(" (9999.0000 items/s)"); got != want {
+		t.Errorf("speedFieldWidth = %d, want %d", got, want)
+	}
+}
+
+func TestEnableSpeedMidRun(t *testing.T) {
+	c, clk, _ := newTestBar(100, 60)
+	c.Update(10)
+	clk.advance(2000)
+	c.Update(30)
+	clk.advance(1000)
+	c.current = 40
+	c.ShowSpeed(true)
+	want := "[============>                  ]  40/100 (  10.00 items/s)"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestMinSampleInterval(t *testing.T) {
+	c, clk, _ := newTestBar(1000, 60)
+	c.ShowSpeed(true).SetMinSampleInterval(time.Second)
+	c.Render()
+	clk.advance(1000)
+	c.current = 10
+	c.Render() // 10 items/s
+	clk.advance(1)
+	c.current = 20
+	if got := c.Snapshot().Speed; got != 10 {
+		t.Fatalf("Speed = %v, want 10", got)
+	}
+	c.Render() // 间隔不足，不采样
+	if got := c.Snapshot().Speed; got != 10 {
+		t.Errorf("Speed after tiny delta = %v, want 10", got)
+	}
+	clk.advance(999)
+	c.current = 40
+	c.Render()
+	if got := c.Snapshot().Speed; got != 30 {
+		t.Errorf("Speed after accumulation = %v, want 30", got)
+	}
+}
+
+func TestSpeedPercent(t *testing.T) {
+	c, clk, _ := newTestBar(400, 60)
+	c.ShowSpeed(true).SetSpeedUnit(SpeedPercent)
+	c.Render()
+	clk.advance(2000)
+	c.current = 6
+	want := "[>                                  ]   6/400 (   0.75 %/s)"
+	if got := c.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatBitRate(t *testing.T) {
+	tests := []struct {
+		bits float64
+		want string
+	}{
+		{0, "  0 bps"},
+		{999, "999 bps"},
+		{999.5, "  1.0 Kbps"},
+		{1000, "  1.0 Kbps"},
+		{999_949, "999.9 Kbps"},
+		{999_950, "  1.0 Mbps"},
+		{12_500_000, " 12.5 Mbps"},
+		{1e9, "  1.0 Gbps"},
+		{1e21, "1000.0 Ebps"},
+	}
+	for _, tt := range tests {
+		if got := formatBitRate(tt.bits); got != tt.want {
+			t.Errorf("formatBitRate(%v) = %q, want %q", tt.bits, got, tt.want)
+		}
+	}
+
+	// 字节数按 8 倍换算为比特
+	c, _, _ := newTestBar(100, 40)
+	c.SetUnit(UnitBytes).SetSpeedUnit(SpeedBits)
+	if got, want := c.formatSpeed(125_000), "  1.0 Mbps"; got != want {
+		t.Errorf("formatSpeed() = %q, want %q", got, want)
+	}
+}
+
+func TestSpeedFieldWidthIECLabels(t *testing.T) {
+	tests := []struct {
+		speedUnit SpeedUnit
+		iec       bool
+		want      string
+	}{
+		{SpeedBytes, false, " (1023.9 KB/s)"},
+		{SpeedBytes, true, " (1023.9 MiB/s)"},
+		{SpeedBytesDecimal, false, " ( 999.9 MB/s)"},
+		{SpeedBytesDecimal, true, " ( 999.9 MB/s)"},
+	}
+	for _, tt := range tests {
+		c, _, _ := newTestBar(100, 40)
+		c.SetUnit(UnitBytes).SetSpeedUnit(tt.speedUnit).SetIECLabels(tt.iec)
+		if got, want := c.speedFieldWidth(), displayWidth(tt.want); got != want {
+			t.Errorf("speedUnit=%d iec=%v: speedFieldWidth = %d, want %d", tt.speedUnit, tt.iec, got, want)
+		}
+		if w := displayWidth(" (" + c.formatSpeed(5<<20) + ")"); w > c.speedFieldWidth() {
+			t.Errorf("speedUnit=%d iec=%v: speed field %d columns exceeds reserved width", tt.speedUnit, tt.iec, w)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package ProgressBar
+
+// 活动指示器依次显示的字符
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// SpinnerPosition 活动指示器的位置
+type SpinnerPosition int
+
+const (
+	SpinnerEnd    SpinnerPosition = iota // 0: 行尾，所有字段和后缀之后(默认)
+	SpinnerBefore                        // 1: 进度条前面
+)
+
+// SetSpinner 是否在进度条旁显示旋转的活动指示器，每次渲染前进一帧，
+// 进度长时间不变时也能看出仍在运行；完成后不再显示
+func (c *Config) SetSpinner(flag bool) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.spinner = flag
+	return c
+}
+
+// SetSpinnerPosition 设置活动指示器显示在行尾还是进度条前面
+func (c *Config) SetSpinnerPosition(pos SpinnerPosition) *Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.spinnerPos = pos
+	return c
+}
+
+// 本次渲染的活动指示器，未开启或已完成时为空，调用方需持有锁
+func (c *Config) nextSpinner() string {
+	if !c.spinner || c.snap.Done {
+		return ""
+	}
+	frame := spinnerFrames[c.spinFrame%len(spinnerFrames)]
+	c.spinFrame++
+	return frame
+}
@@ -0,0 +1,96 @@
+package ProgressBar
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 单个字符的显示宽度：组合字符为 0，东亚宽字符和 emoji 为 2，其余为 1
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || r == 0x200B:
+		return 0
+	case r < 0x1100:
+		return 1
+	case r <= 0x115F, // 谚文字母
+		r == 0x2B1B || r == 0x2B1C || r == 0x2B50 || r == 0x2B55, // ⬛⬜⭐⭕
+		r >= 0x2E80 && r <= 0x303E,                               // CJK 部首、标点
+		r >= 0x3041 && r <= 0x33FF,                               // 假名、CJK 符号
+		r >= 0x3400 && r <= 0x4DBF,                               // CJK 扩展 A
+		r >= 0x4E00 && r <= 0x9FFF,                               // CJK 统一汉字
+		r >= 0xA000 && r <= 0xA4CF,                               // 彝文
+		r >= 0xAC00 && r <= 0xD7A3,                               // 谚文音节
+		r >= 0xF900 && r <= 0xFAFF,                               // CJK 兼容汉字
+		r >= 0xFE30 && r <= 0xFE4F,                               // CJK 兼容形式
+		r >= 0xFF00 && r <= 0xFF60,                               // 全角字符
+		r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F300 && r <= 0x1F64F, // emoji
+		r >= 0x1F680 && r <= 0x1F6FF,
+		r >= 0x1F7E0 && r <= 0x1F7EB, // 彩色圆形、方块
+		r >= 0x1F900 && r <= 0x1FAFF,
+		r >= 0x20000 && r <= 0x3FFFD: // CJK 扩展 B 及以后
+		return 2
+	}
+	return 1
+}
+
+// 跳过 s[i] 开始的 ANSI 转义序列(ESC [ ... 终止字节)，返回序列之后的位置；不是转义序列时返回 i
+func skipEscape(rs []rune, i int) int {
+	if rs[i] != 0x1b || i+1 >= len(rs) || rs[i+1] != '[' {
+		return i
+	}
+	for i += 2; i < len(rs) && (rs[i] < 0x40 || rs[i] > 0x7e); i++ {
+	}
+	if i < len(rs) {
+		i++
+	}
+	return i
+}
+
+// 字符串的显示宽度(列数)，ANSI 转义序列不计宽度
+func displayWidth(s string) int {
+	cols := 0
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		if j := skipEscape(rs, i); j > i {
+			i = j
+			continue
+		}
+		cols += runeWidth(rs[i])
+		i++
+	}
+	return cols
+}
+
+// 将字符串截断到不超过 w 列，ANSI 转义序列不计宽度且不会被截断；
+// 截断时若包含转义序列则在末尾追加重置，避免颜色泄漏到后续输出
+func truncateToWidth(s string, w int) string {
+	cols := 0
+	hasEscape := false
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		if j := skipEscape(rs, i); j > i {
+			hasEscape = true
+			i = j
+			continue
+		}
+		cols += runeWidth(rs[i])
+		if cols > w {
+			out := string(rs[:i])
+			if hasEscape {
+				out += "\x1b[0m"
+			}
+			return out
+		}
+		i++
+	}
+	return s
+}
+
+// 在右侧补空格直到显示宽度达到 w，已超过时原样返回
+func padToWidth(s string, w int) string {
+	if n := displayWidth(s); n < w {
+		return s + strings.Repeat(" ", w-n)
+	}
+	return s
+}
@@ -0,0 +1,76 @@
+package ProgressBar
+
+import "testing"
+
+func TestTruncateToWidth(t *testing.T) {
+	tests := []struct {
+		in   string
+		w    int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 3, "hel"},
+		{"进度条", 4, "进度"},
+		{"进度条", 5, "进度"},
+		{"\x1b[32m====\x1b[0m]", 2, "\x1b[32m==\x1b[0m"},
+	}
+	for _, tt := range tests {
+		if got := truncateToWidth(tt.in, tt.w); got != tt.want {
+			t.Errorf("truncateToWidth(%q, %d) = %q, want %q", tt.in, tt.w, got, tt.want)
+		}
+	}
+}
+
+func TestLineNeverWraps(t *testing.T) {
+	c, _, buf := newTestBar(10, 12)
+	c.ShowPercent(true)
+	c.Update(5)
+	want := "\r[] 50.0% ( " // 字段总宽超过终端宽度时截断
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"已用", 4},
+		{"a已b", 4},
+		{"ｆｕｌｌ", 8},
+		{"🚀 go", 5},
+		{"é", 1},
+		{"\x1b[92m===\x1b[0m", 3},
+		{"한국어", 6},
+	}
+	for _, tt := range tests {
+		if got := displayWidth(tt.in); got != tt.want {
+			t.Errorf("displayWidth(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPadToWidth(t *testing.T) {
+	if got := padToWidth("项/s", 6); got != "项/s  " {
+		t.Errorf("padToWidth = %q", got)
+	}
+	if got := padToWidth("🚀🚀", 3); got != "🚀🚀" {
+		t.Errorf("padToWidth = %q", got)
+	}
+	if got := truncateToWidth("a🚀b", 2); got != "a" {
+		t.Errorf("truncateToWidth = %q", got)
+	}
+}
+
+func TestRenderCJKLabelsFitWidth(t *testing.T) {
+	c, _, _ := newTestBar(100, 40)
+	c.SetLabels(ChineseLabels)
+	c.ShowUsedTime(true)
+	c.current = 50
+	if got := displayWidth(c.Render()); got != 39 {
+		t.Errorf("display width = %d, want 39: %q", got, c.Render())
+	}
+}